@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// BlockResults holds every tx of a block with its execution result together with the
+// events emitted outside of txs, it is built from /block and /block_results only so
+// it also works on nodes with tx indexing disabled.
+type BlockResults struct {
+	Height int64
+	Time   time.Time
+	Txs    []*types.TxResponse
+	// SkippedTxCount is the number of txs that could not be decoded by the tx config
+	SkippedTxCount   int
+	BeginBlockEvents []abci.Event
+	EndBlockEvents   []abci.Event
+}
+
+// FinalizeBlockEvents returns the begin and end block events, which is where sudo
+// callbacks triggered outside of txs (e.g. cron or interchain query results) show up.
+func (r *BlockResults) FinalizeBlockEvents() []abci.Event {
+	events := make([]abci.Event, 0, len(r.BeginBlockEvents)+len(r.EndBlockEvents))
+	events = append(events, r.BeginBlockEvents...)
+	return append(events, r.EndBlockEvents...)
+}
+
+// FinalizeBlockContractEvents decodes the contract events of FinalizeBlockEvents.
+func (r *BlockResults) FinalizeBlockContractEvents() []ContractEvent {
+	events := make([]ContractEvent, 0)
+	for _, event := range r.FinalizeBlockEvents() {
+		if !isContractEventType(event.Type) {
+			continue
+		}
+		events = append(events, splitContractEvent(event.Type, abciAttributes(event.Attributes), MsgIndexUnknown)...)
+	}
+	return events
+}
+
+// intoAny is implemented by the txs decoded by the auth tx config
+type intoAny interface {
+	AsAny() *codecTypes.Any
+}
+
+func (c *Client) QueryBlockResults(height int64) (*ctypes.ResultBlockResults, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryBlockResults(height)
+}
+
+func (c *Client) queryBlockResults(height int64) (*ctypes.ResultBlockResults, error) {
+	cc, err := c.retry(func() (interface{}, error) {
+		node, ok := c.Ctx().Client.(rpcClient.SignClient)
+		if !ok {
+			return nil, fmt.Errorf("rpc client doesn't support block results")
+		}
+		return node.BlockResults(context.Background(), &height)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*ctypes.ResultBlockResults), nil
+}
+
+func (c *Client) queryBlock(height int64) (*ctypes.ResultBlock, error) {
+	cc, err := c.retry(func() (interface{}, error) {
+		node, err := c.Ctx().GetNode()
+		if err != nil {
+			return nil, err
+		}
+		return node.Block(context.Background(), &height)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*ctypes.ResultBlock), nil
+}
+
+// GetBlockResults gets all txs of height with their results in two rpc calls instead of
+// paging through tx_search, txs that can't be decoded are skipped like GetBlockTxsWithParseErrSkip.
+func (c *Client) GetBlockResults(height int64) (*BlockResults, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	block, err := c.queryBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	blockResults, err := c.queryBlockResults(height)
+	if err != nil {
+		return nil, err
+	}
+	if len(block.Block.Txs) != len(blockResults.TxsResults) {
+		return nil, fmt.Errorf("block %d txs len: %d not match results len: %d", height, len(block.Block.Txs), len(blockResults.TxsResults))
+	}
+
+	ret := &BlockResults{
+		Height:           height,
+		Time:             block.Block.Time,
+		Txs:              make([]*types.TxResponse, 0, len(block.Block.Txs)),
+		BeginBlockEvents: blockResults.BeginBlockEvents,
+		EndBlockEvents:   blockResults.EndBlockEvents,
+	}
+	timestamp := block.Block.Time.Format(time.RFC3339)
+	for i, txBts := range block.Block.Txs {
+		txResult := blockResults.TxsResults[i]
+		if txResult == nil {
+			return nil, fmt.Errorf("block %d tx %d result is nil", height, i)
+		}
+
+		tx, err := c.clientCtx.TxConfig.TxDecoder()(txBts)
+		if err != nil {
+			ret.SkippedTxCount++
+			continue
+		}
+		p, ok := tx.(intoAny)
+		if !ok {
+			ret.SkippedTxCount++
+			continue
+		}
+
+		resTx := &ctypes.ResultTx{
+			Hash:     txBts.Hash(),
+			Height:   height,
+			Index:    uint32(i),
+			TxResult: *txResult,
+			Tx:       txBts,
+		}
+		ret.Txs = append(ret.Txs, types.NewResponseResultTx(resTx, p.AsAny(), timestamp))
+	}
+	return ret, nil
+}
+
+// GetBlockTxsByBlockResults is an alternative of GetBlockTxsWithParseErrSkip that doesn't
+// depend on the tx indexer.
+func (c *Client) GetBlockTxsByBlockResults(height int64) ([]*types.TxResponse, error) {
+	results, err := c.GetBlockResults(height)
+	if err != nil {
+		return nil, err
+	}
+	return results.Txs, nil
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+)
+
+const cronContract = "neutron1nc5tatafv6eyq7llkr2gv50ff9e22mnf70qgjlv737ktmt4eswrqcd0mrx"
+
+func contractEvent(eventType, contract, action string) abci.Event {
+	return abci.Event{Type: eventType, Attributes: []abci.EventAttribute{
+		{Key: "_contract_address", Value: contract},
+		{Key: "action", Value: action},
+	}}
+}
+
+// newBlockResultsChain commits a block with an execute tx of failoverContract and sudo
+// events of cronContract, followed by a block with a tx that can't be decoded.
+func newBlockResultsChain(t *testing.T) (*Client, int64) {
+	c, chain := newMockChainClient(t)
+	chain.SetDeliverTxHandler(func([]byte) abci.ResponseDeliverTx {
+		return abci.ResponseDeliverTx{Events: []abci.Event{contractEvent("wasm", failoverContract, "era_update")}}
+	})
+	if _, err := c.SendContractExecuteMsg(failoverContract, []byte(`{"era_update":{}}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	height := chain.Height()
+	err := chain.SetBlockEvents(height,
+		[]abci.Event{contractEvent("wasm", cronContract, "begin_blocker")},
+		[]abci.Event{{Type: "coin_spent"}, contractEvent("wasm-ica_ack", cronContract, "end_blocker")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.CommitBlock(clienttest.Tx{Bytes: []byte("not a tx")})
+	return c, height
+}
+
+func TestGetBlockResults(t *testing.T) {
+	c, height := newBlockResultsChain(t)
+
+	results, err := c.GetBlockResults(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Txs) != 1 || results.SkippedTxCount != 0 || results.Txs[0].Height != height {
+		t.Fatalf("unexpected block results %+v", results)
+	}
+	if events := DecodeContractEvents(results.Txs[0]); len(events) != 1 || events[0].Contract != failoverContract {
+		t.Fatalf("unexpected tx events %+v", events)
+	}
+	if events := results.FinalizeBlockEvents(); len(events) != 3 || events[0].Type != "wasm" || events[1].Type != "coin_spent" {
+		t.Fatalf("unexpected finalize block events %+v", events)
+	}
+	events := results.FinalizeBlockContractEvents()
+	if len(events) != 2 || events[0].Contract != cronContract || events[1].CustomType() != "ica_ack" {
+		t.Fatalf("unexpected finalize block contract events %+v", events)
+	}
+	if action, _ := events[1].Attribute("action"); action != "end_blocker" || events[1].MsgIndex != MsgIndexUnknown {
+		t.Fatalf("unexpected end block contract event %+v", events[1])
+	}
+
+	skipped, err := c.GetBlockResults(height + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped.Txs) != 0 || skipped.SkippedTxCount != 1 {
+		t.Fatalf("undecodable tx not skipped %+v", skipped)
+	}
+}
+
+func TestScannerUseBlockResults(t *testing.T) {
+	c, height := newBlockResultsChain(t)
+	bs, err := utils.NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := NewScanner(c, bs, ScannerConfig{StartHeight: 1, Interval: time.Hour, UseBlockResults: true}, log.NewLog("client", "scanner"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handled := make([]int64, 0)
+	scanner.AddHandler(EventFilter{ContractAddress: failoverContract}, func(height int64, _ *types.TxResponse) error {
+		handled = append(handled, height)
+		return nil
+	})
+
+	last, err := scanner.ScanOnce()
+	if err != nil || last != height+1 {
+		t.Fatalf("scanned to %d: %v", last, err)
+	}
+	if len(handled) != 1 || handled[0] != height {
+		t.Fatalf("handled txs at %v, want %d", handled, height)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	rpcClient "github.com/cometbft/cometbft/rpc/client"
 	rpcHttp "github.com/cometbft/cometbft/rpc/client/http"
+	jsonrpcClient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -16,6 +18,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types"
 	xAuthTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var denom = "untrn"
@@ -30,11 +33,19 @@ type Client struct {
 	accountNumber       uint64
 	accountPrefix       string
 	rpcClientIndex      int
+	endpoints           *endpointRegistry
 	changeEndpointMutex sync.Mutex
 	logger              log.Logger
+	metrics             *clientMetrics
+	tracer              trace.Tracer
+	rpcTimeout          time.Duration
+	txWaitTimeout       time.Duration
+	grpcEndpointList    []string
+	grpc                *grpcEndpoints
+	verifier            *verifier
 }
 
-func NewClient(k keyring.Keyring, fromName, gasPrice, accountPrefix string, endPointList []string, logger log.Logger) (*Client, error) {
+func NewClient(k keyring.Keyring, fromName, gasPrice, accountPrefix string, endPointList []string, logger log.Logger, opts ...Option) (*Client, error) {
 	if len(endPointList) == 0 {
 		return nil, fmt.Errorf("no endpoint")
 	}
@@ -46,15 +57,33 @@ func NewClient(k keyring.Keyring, fromName, gasPrice, accountPrefix string, endP
 	retClient := &Client{
 		accountPrefix:  accountPrefix,
 		rpcClientIndex: 0,
+		endpoints:      newEndpointRegistry(endPointList),
 		logger:         logger,
 	}
+	for _, opt := range opts {
+		if err := opt(retClient); err != nil {
+			return nil, err
+		}
+	}
 
 	for _, endPoint := range endPointList {
-		rClient, err := rpcHttp.New(endPoint, "/websocket")
+		httpClient, err := jsonrpcClient.DefaultHTTPClient(endPoint)
 		if err != nil {
 			return nil, err
 		}
-		retClient.rpcClientList = append(retClient.rpcClientList, rClient)
+		httpClient.Timeout = retClient.rpcTimeout
+		rClient, err := rpcHttp.NewWithClient(endPoint, "/websocket", httpClient)
+		if err != nil {
+			return nil, err
+		}
+		retClient.rpcClientList = append(retClient.rpcClientList, newInstrumentedRPC(rClient, endPoint, retClient.metrics))
+	}
+	if len(retClient.grpcEndpointList) != 0 {
+		grpcEndpoints, err := newGrpcEndpoints(retClient.grpcEndpointList, encodingConfig.InterfaceRegistry, retClient.rpcTimeout, retClient.metrics, logger)
+		if err != nil {
+			return nil, err
+		}
+		retClient.grpc = grpcEndpoints
 	}
 
 	if len(fromName) != 0 {
@@ -210,8 +239,14 @@ func (c *Client) Sign(fromName string, toBeSigned []byte) ([]byte, cryptoTypes.P
 	return c.clientCtx.Keyring.Sign(fromName, toBeSigned)
 }
 
+// Ctx returns the client ctx, module queries made with it go to the current grpc endpoint
+// when grpc is enabled.
 func (c *Client) Ctx() client.Context {
-	return c.clientCtx
+	clientCtx := c.abciCtx()
+	if conn := c.grpc.current(); conn != nil {
+		return clientCtx.WithGRPCClient(conn)
+	}
+	return clientCtx
 }
 
 func (c *Client) GetRpcClient() *rpcClient.Client {
@@ -223,10 +258,17 @@ func (c *Client) ChangeEndpoint() {
 	defer c.changeEndpointMutex.Unlock()
 
 	willUseIndex := (c.rpcClientIndex + 1) % len(c.rpcClientList)
-	c.clientCtx = c.clientCtx.WithClient(c.rpcClientList[willUseIndex])
+	// only the rpc client field is written, other fields are read without the lock
+	c.clientCtx.Client = c.rpcClientList[willUseIndex]
+	// wasm clients hold the ctx they were built with
+	c.msgClient = xWasmTypes.NewMsgClient(c.clientCtx)
+	c.queryClient = xWasmTypes.NewQueryClient(c.clientCtx)
 	c.rpcClientIndex = willUseIndex
+	c.metrics.observeEndpointSwitch()
 }
 
 func (c *Client) CurrentEndpointIndex() int {
+	c.changeEndpointMutex.Lock()
+	defer c.changeEndpointMutex.Unlock()
 	return c.rpcClientIndex
 }
@@ -1,3 +1,8 @@
+//go:build testnet
+
+// Tests against the public pion-1 testnet, run them with go test -tags testnet. Hermetic
+// tests use clienttest instead.
+
 package client
 
 import (
@@ -0,0 +1,525 @@
+// Package clienttest runs an in-process fake CometBFT node for hermetic tests of code built
+// on the client package. The node speaks the real JSON-RPC wire format, so a client created
+// with NewClient against Chain.URL() works unchanged.
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtBytes "github.com/cometbft/cometbft/libs/bytes"
+	cmtLog "github.com/cometbft/cometbft/libs/log"
+	cmtQuery "github.com/cometbft/cometbft/libs/pubsub/query"
+	"github.com/cometbft/cometbft/p2p"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpcServer "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpcTypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	tmTypes "github.com/cometbft/cometbft/types"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	xAuthTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// Query paths served by the seeding helpers
+const (
+	AccountQueryPath    = "/cosmos.auth.v1beta1.Query/Account"
+	BalanceQueryPath    = "/cosmos.bank.v1beta1.Query/Balance"
+	SmartQueryPath      = "/cosmwasm.wasm.v1.Query/SmartContractState"
+	SimulateQueryPath   = "/cosmos.tx.v1beta1.Service/Simulate"
+	defaultSimulatedGas = 100000
+)
+
+// QueryHandler answers an abci query with the marshaled response or an error that is
+// returned to the client as a failed query.
+type QueryHandler func(data []byte, height int64) ([]byte, error)
+
+// DeliverTxHandler returns the result of a broadcast tx committed by the chain. It is called
+// without the chain lock held and may use the Chain methods.
+type DeliverTxHandler func(tx []byte) abci.ResponseDeliverTx
+
+// Tx is a tx of a committed block with its result.
+type Tx struct {
+	Bytes  []byte
+	Result abci.ResponseDeliverTx
+}
+
+type block struct {
+	block            *tmTypes.Block
+	txs              []Tx
+	beginBlockEvents []abci.Event
+	endBlockEvents   []abci.Event
+}
+
+// Chain is a fake node. Blocks are only produced by CommitBlock, or by broadcasts when auto
+// commit is on (the default): every accepted broadcast tx is committed in its own block.
+type Chain struct {
+	chainId string
+	server  *httptest.Server
+
+	mutex         sync.Mutex
+	blocks        []*block
+	queries       map[string]QueryHandler
+	accounts      map[string]*xAuthTypes.BaseAccount
+	balances      map[string]types.Coin
+	smartQueries  map[string][]byte
+	simulatedGas  uint64
+	checkTx       abci.ResponseCheckTx
+	deliverTx     DeliverTxHandler
+	autoCommit    bool
+	broadcastTxs  [][]byte
+	blockInterval time.Duration
+}
+
+// NewChain starts a node with an empty block at height 1, Close must be called to stop it.
+func NewChain(chainId string) *Chain {
+	c := &Chain{
+		chainId:       chainId,
+		queries:       make(map[string]QueryHandler),
+		accounts:      make(map[string]*xAuthTypes.BaseAccount),
+		balances:      make(map[string]types.Coin),
+		smartQueries:  make(map[string][]byte),
+		simulatedGas:  defaultSimulatedGas,
+		autoCommit:    true,
+		blockInterval: 6 * time.Second,
+	}
+	c.queries[AccountQueryPath] = c.queryAccount
+	c.queries[BalanceQueryPath] = c.queryBalance
+	c.queries[SmartQueryPath] = c.querySmart
+	c.queries[SimulateQueryPath] = c.simulate
+	c.deliverTx = c.defaultDeliverTx
+	c.commitBlock(nil)
+
+	mux := http.NewServeMux()
+	rpcServer.RegisterRPCFuncs(mux, c.routes(), cmtLog.NewNopLogger())
+	c.server = httptest.NewServer(mux)
+	return c
+}
+
+func (c *Chain) URL() string {
+	return c.server.URL
+}
+
+func (c *Chain) Close() {
+	c.server.Close()
+}
+
+func (c *Chain) ChainId() string {
+	return c.chainId
+}
+
+func (c *Chain) Height() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return int64(len(c.blocks))
+}
+
+// HandleQuery serves abci queries of path with h, replacing any previous handler.
+func (c *Chain) HandleQuery(path string, h QueryHandler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.queries[path] = h
+}
+
+// SetAccount seeds the account returned by the auth Account query. The sequence is not
+// increased by broadcasts, set the account again to move it.
+func (c *Chain) SetAccount(account *xAuthTypes.BaseAccount) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.accounts[account.Address] = account
+}
+
+func (c *Chain) SetBalance(address string, coin types.Coin) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.balances[address+"/"+coin.Denom] = coin
+}
+
+// SetSmartQueryResponse seeds the response of a contract to req, req is matched after
+// compacting its json.
+func (c *Chain) SetSmartQueryResponse(contract string, req, resp []byte) error {
+	key, err := smartQueryKey(contract, req)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.smartQueries[key] = resp
+	return nil
+}
+
+// SetSimulatedGas sets the gas used reported by tx simulation.
+func (c *Chain) SetSimulatedGas(gas uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.simulatedGas = gas
+}
+
+// SetCheckTxResult makes broadcasts return code, a non zero code rejects the tx.
+func (c *Chain) SetCheckTxResult(code uint32, codespace, log string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.checkTx = abci.ResponseCheckTx{Code: code, Codespace: codespace, Log: log}
+}
+
+// SetDeliverTxHandler sets the result of committed broadcast txs, by default code 0 with
+// the simulated gas.
+func (c *Chain) SetDeliverTxHandler(h DeliverTxHandler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deliverTx = h
+}
+
+// SetAutoCommit turns off committing broadcast txs, they are then only recorded.
+func (c *Chain) SetAutoCommit(autoCommit bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.autoCommit = autoCommit
+}
+
+// BroadcastTxs returns all txs accepted by broadcast in order.
+func (c *Chain) BroadcastTxs() [][]byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([][]byte{}, c.broadcastTxs...)
+}
+
+// SetBlockEvents sets the begin and end block events /block_results returns for the
+// committed block at height.
+func (c *Chain) SetBlockEvents(height int64, beginBlock, endBlock []abci.Event) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if height < 1 || height > int64(len(c.blocks)) {
+		return fmt.Errorf("block %d not committed", height)
+	}
+	b := c.blocks[height-1]
+	b.beginBlockEvents, b.endBlockEvents = beginBlock, endBlock
+	return nil
+}
+
+// CommitBlock appends a block with txs and returns its height.
+func (c *Chain) CommitBlock(txs ...Tx) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.commitBlock(txs)
+}
+
+func (c *Chain) commitBlock(txs []Tx) int64 {
+	height := int64(len(c.blocks)) + 1
+	blockTxs := make([]tmTypes.Tx, len(txs))
+	for i, tx := range txs {
+		blockTxs[i] = tx.Bytes
+	}
+	b := tmTypes.MakeBlock(height, blockTxs, &tmTypes.Commit{}, nil)
+	b.Header.ChainID = c.chainId
+	b.Header.Time = time.Unix(1700000000, 0).UTC().Add(time.Duration(height) * c.blockInterval)
+	if height > 1 {
+		prev := c.blocks[height-2].block
+		b.Header.LastBlockID = tmTypes.BlockID{Hash: prev.Hash()}
+	}
+	c.blocks = append(c.blocks, &block{block: b, txs: txs})
+	return height
+}
+
+func (c *Chain) routes() map[string]*rpcServer.RPCFunc {
+	return map[string]*rpcServer.RPCFunc{
+		"status":             rpcServer.NewRPCFunc(c.status, ""),
+		"abci_info":          rpcServer.NewRPCFunc(c.abciInfo, ""),
+		"abci_query":         rpcServer.NewRPCFunc(c.abciQuery, "path,data,height,prove"),
+		"block":              rpcServer.NewRPCFunc(c.blockAt, "height"),
+		"block_results":      rpcServer.NewRPCFunc(c.blockResults, "height"),
+		"tx":                 rpcServer.NewRPCFunc(c.tx, "hash,prove"),
+		"tx_search":          rpcServer.NewRPCFunc(c.txSearch, "query,prove,page,per_page,order_by"),
+		"broadcast_tx_sync":  rpcServer.NewRPCFunc(c.broadcastTxSync, "tx"),
+		"broadcast_tx_async": rpcServer.NewRPCFunc(c.broadcastTxSync, "tx"),
+	}
+}
+
+func (c *Chain) status(_ *rpcTypes.Context) (*ctypes.ResultStatus, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	latest := c.blocks[len(c.blocks)-1].block
+	earliest := c.blocks[0].block
+	return &ctypes.ResultStatus{
+		NodeInfo: p2p.DefaultNodeInfo{Network: c.chainId},
+		SyncInfo: ctypes.SyncInfo{
+			LatestBlockHash:     latest.Hash(),
+			LatestBlockHeight:   latest.Height,
+			LatestBlockTime:     latest.Time,
+			EarliestBlockHash:   earliest.Hash(),
+			EarliestBlockHeight: earliest.Height,
+			EarliestBlockTime:   earliest.Time,
+		},
+	}, nil
+}
+
+func (c *Chain) abciInfo(_ *rpcTypes.Context) (*ctypes.ResultABCIInfo, error) {
+	return &ctypes.ResultABCIInfo{Response: abci.ResponseInfo{LastBlockHeight: c.Height()}}, nil
+}
+
+func (c *Chain) abciQuery(_ *rpcTypes.Context, path string, data cmtBytes.HexBytes, height int64, _ bool) (*ctypes.ResultABCIQuery, error) {
+	c.mutex.Lock()
+	handler, ok := c.queries[path]
+	latest := int64(len(c.blocks))
+	c.mutex.Unlock()
+
+	if height == 0 {
+		height = latest
+	}
+	if height > latest {
+		return queryError(height, sdkErrors.ErrInvalidHeight.Wrapf("height %d is greater than latest %d", height, latest)), nil
+	}
+	if !ok {
+		return queryError(height, sdkErrors.ErrUnknownRequest.Wrapf("unknown query path %s", path)), nil
+	}
+	value, err := handler(data, height)
+	if err != nil {
+		return queryError(height, err), nil
+	}
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value, Height: height}}, nil
+}
+
+func queryError(height int64, err error) *ctypes.ResultABCIQuery {
+	codespace, code, log := sdkErrors.ABCIInfo(err, false)
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: code, Codespace: codespace, Log: log, Height: height}}
+}
+
+func (c *Chain) getBlock(heightPtr *int64) (*block, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	height := int64(len(c.blocks))
+	if heightPtr != nil && *heightPtr != 0 {
+		height = *heightPtr
+	}
+	if height < 1 || height > int64(len(c.blocks)) {
+		return nil, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d", height, len(c.blocks))
+	}
+	return c.blocks[height-1], nil
+}
+
+func (c *Chain) blockAt(_ *rpcTypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
+	b, err := c.getBlock(heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultBlock{BlockID: tmTypes.BlockID{Hash: b.block.Hash()}, Block: b.block}, nil
+}
+
+func (c *Chain) blockResults(_ *rpcTypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
+	b, err := c.getBlock(heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*abci.ResponseDeliverTx, len(b.txs))
+	for i := range b.txs {
+		result := b.txs[i].Result
+		results[i] = &result
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return &ctypes.ResultBlockResults{
+		Height:           b.block.Height,
+		TxsResults:       results,
+		BeginBlockEvents: b.beginBlockEvents,
+		EndBlockEvents:   b.endBlockEvents,
+	}, nil
+}
+
+func (c *Chain) tx(_ *rpcTypes.Context, hash []byte, _ bool) (*ctypes.ResultTx, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, b := range c.blocks {
+		for i, tx := range b.txs {
+			if bytes.Equal(tmTypes.Tx(tx.Bytes).Hash(), hash) {
+				return resultTx(b, i), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("tx (%X) not found", hash)
+}
+
+func resultTx(b *block, index int) *ctypes.ResultTx {
+	tx := b.txs[index]
+	return &ctypes.ResultTx{
+		Hash:     tmTypes.Tx(tx.Bytes).Hash(),
+		Height:   b.block.Height,
+		Index:    uint32(index),
+		TxResult: tx.Result,
+		Tx:       tx.Bytes,
+	}
+}
+
+func (c *Chain) txSearch(_ *rpcTypes.Context, query string, _ bool, pagePtr, perPagePtr *int, orderBy string) (*ctypes.ResultTxSearch, error) {
+	q, err := cmtQuery.New(query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	matched := make([]*ctypes.ResultTx, 0)
+	for _, b := range c.blocks {
+		for i, tx := range b.txs {
+			ok, err := q.Matches(txEvents(b.block.Height, tx))
+			if err != nil {
+				c.mutex.Unlock()
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, resultTx(b, i))
+			}
+		}
+	}
+	c.mutex.Unlock()
+
+	if orderBy == "desc" {
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Height > matched[j].Height })
+	}
+	page, perPage := 1, 30
+	if pagePtr != nil {
+		page = *pagePtr
+	}
+	if perPagePtr != nil {
+		perPage = *perPagePtr
+	}
+	start := (page - 1) * perPage
+	if start < 0 || (start >= len(matched) && len(matched) != 0) {
+		return nil, fmt.Errorf("page should be within [1, %d] range, given %d", (len(matched)+perPage-1)/perPage, page)
+	}
+	end := start + perPage
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return &ctypes.ResultTxSearch{Txs: matched[start:end], TotalCount: len(matched)}, nil
+}
+
+func txEvents(height int64, tx Tx) map[string][]string {
+	events := map[string][]string{
+		"tx.height": {strconv.FormatInt(height, 10)},
+		"tx.hash":   {fmt.Sprintf("%X", tmTypes.Tx(tx.Bytes).Hash())},
+	}
+	for _, event := range tx.Result.Events {
+		for _, attr := range event.Attributes {
+			key := event.Type + "." + attr.Key
+			events[key] = append(events[key], attr.Value)
+		}
+	}
+	return events
+}
+
+// broadcastTxSync runs the DeliverTxHandler without the chain lock, so the handler may call
+// back into the chain.
+func (c *Chain) broadcastTxSync(_ *rpcTypes.Context, tx tmTypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	c.mutex.Lock()
+	res := &ctypes.ResultBroadcastTx{
+		Code:      c.checkTx.Code,
+		Codespace: c.checkTx.Codespace,
+		Log:       c.checkTx.Log,
+		Hash:      tx.Hash(),
+	}
+	if res.Code != 0 {
+		c.mutex.Unlock()
+		return res, nil
+	}
+	c.broadcastTxs = append(c.broadcastTxs, tx)
+	autoCommit, deliverTx := c.autoCommit, c.deliverTx
+	c.mutex.Unlock()
+
+	if autoCommit {
+		result := deliverTx(tx)
+		c.CommitBlock(Tx{Bytes: tx, Result: result})
+	}
+	return res, nil
+}
+
+func (c *Chain) defaultDeliverTx(_ []byte) abci.ResponseDeliverTx {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return abci.ResponseDeliverTx{GasWanted: int64(c.simulatedGas), GasUsed: int64(c.simulatedGas)}
+}
+
+func (c *Chain) queryAccount(data []byte, _ int64) ([]byte, error) {
+	req := xAuthTypes.QueryAccountRequest{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	account, ok := c.accounts[req.Address]
+	c.mutex.Unlock()
+	if !ok {
+		return nil, sdkErrors.ErrKeyNotFound.Wrapf("account %s not found", req.Address)
+	}
+	any, err := codecTypes.NewAnyWithValue(account)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(&xAuthTypes.QueryAccountResponse{Account: any})
+}
+
+func (c *Chain) queryBalance(data []byte, _ int64) ([]byte, error) {
+	req := xBankTypes.QueryBalanceRequest{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	coin, ok := c.balances[req.Address+"/"+req.Denom]
+	c.mutex.Unlock()
+	if !ok {
+		coin = types.NewInt64Coin(req.Denom, 0)
+	}
+	return marshal(&xBankTypes.QueryBalanceResponse{Balance: &coin})
+}
+
+func (c *Chain) querySmart(data []byte, _ int64) ([]byte, error) {
+	req := xWasmTypes.QuerySmartContractStateRequest{}
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	key, err := smartQueryKey(req.Address, req.QueryData)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	resp, ok := c.smartQueries[key]
+	c.mutex.Unlock()
+	if !ok {
+		return nil, sdkErrors.ErrUnknownRequest.Wrapf("no response seeded for %s query %s", req.Address, req.QueryData)
+	}
+	return marshal(&xWasmTypes.QuerySmartContractStateResponse{Data: resp})
+}
+
+func (c *Chain) simulate(_ []byte, _ int64) ([]byte, error) {
+	c.mutex.Lock()
+	gas := c.simulatedGas
+	c.mutex.Unlock()
+	return marshal(&txTypes.SimulateResponse{
+		GasInfo: &types.GasInfo{GasWanted: gas, GasUsed: gas},
+		Result:  &types.Result{},
+	})
+}
+
+func smartQueryKey(contract string, req []byte) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, req); err != nil {
+		return "", fmt.Errorf("smart query is not json: %w", err)
+	}
+	return contract + "/" + buf.String(), nil
+}
+
+func marshal(msg proto.Message) ([]byte, error) {
+	return proto.Marshal(msg)
+}
@@ -0,0 +1,315 @@
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Fault is a failure injected by Proxy into the traffic of an endpoint.
+type Fault int
+
+const (
+	// FaultNone forwards every request untouched.
+	FaultNone Fault = iota
+	// FaultConnectionRefused stops listening, dials fail with ECONNREFUSED.
+	FaultConnectionRefused
+	// FaultConnectionDrop accepts the request and closes the connection without a response.
+	FaultConnectionDrop
+	// FaultTimeout holds the request until the client gives up.
+	FaultTimeout
+	// FaultMalformedJSON answers 200 with a body that is not valid json.
+	FaultMalformedJSON
+	// FaultHTTP500 answers 500 with a plain text body, like a load balancer in front of a dead node.
+	FaultHTTP500
+	// FaultStaleHeight reports a latest height SetStaleLag blocks behind the target and rejects
+	// queries above it, like a node that fell behind.
+	FaultStaleHeight
+	// FaultPartialResponse forwards the response but cuts the body in half.
+	FaultPartialResponse
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultConnectionRefused:
+		return "connection refused"
+	case FaultConnectionDrop:
+		return "connection drop"
+	case FaultTimeout:
+		return "timeout"
+	case FaultMalformedJSON:
+		return "malformed json"
+	case FaultHTTP500:
+		return "http 500"
+	case FaultStaleHeight:
+		return "stale height"
+	case FaultPartialResponse:
+		return "partial response"
+	default:
+		return "unknown"
+	}
+}
+
+// Proxy sits between a client and an rpc endpoint and injects faults into its traffic.
+// It keeps its address across FaultConnectionRefused, so the client needn't be recreated.
+type Proxy struct {
+	target string
+	addr   string
+	client *http.Client
+
+	mutex    sync.Mutex
+	fault    Fault
+	staleLag int64
+	server   *http.Server
+	closed   chan struct{}
+}
+
+// NewProxy starts a proxy forwarding to the rpc endpoint at target.
+func NewProxy(target string) *Proxy {
+	p := &Proxy{
+		target:   target,
+		client:   &http.Client{},
+		staleLag: 1,
+		closed:   make(chan struct{}),
+	}
+	if err := p.listen("127.0.0.1:0"); err != nil {
+		panic(fmt.Sprintf("clienttest: proxy listen: %s", err))
+	}
+	return p
+}
+
+func (p *Proxy) URL() string {
+	return "http://" + p.addr
+}
+
+// Close stops the proxy and releases requests held by FaultTimeout.
+func (p *Proxy) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	select {
+	case <-p.closed:
+		return
+	default:
+	}
+	close(p.closed)
+	if p.server != nil {
+		p.server.Close()
+	}
+}
+
+func (p *Proxy) Fault() Fault {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.fault
+}
+
+// SetFault switches the fault injected into following requests.
+func (p *Proxy) SetFault(fault Fault) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch {
+	case fault == FaultConnectionRefused && p.server != nil:
+		// kept alive connections are closed too, a closed server can't serve again
+		err := p.server.Close()
+		p.server = nil
+		if err != nil {
+			return err
+		}
+	case fault != FaultConnectionRefused && p.server == nil:
+		if err := p.listen(p.addr); err != nil {
+			return err
+		}
+	}
+	p.fault = fault
+	return nil
+}
+
+// SetStaleLag sets how many blocks FaultStaleHeight reports behind the target, default 1.
+func (p *Proxy) SetStaleLag(lag int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.staleLag = lag
+}
+
+// listen must be called with mutex held or before the proxy is shared.
+func (p *Proxy) listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	p.server = &http.Server{Handler: http.HandlerFunc(p.serveHTTP)}
+	if p.addr == "" {
+		p.addr = listener.Addr().String()
+	}
+	go p.server.Serve(listener) //nolint:errcheck
+	return nil
+}
+
+func (p *Proxy) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	fault, staleLag := p.fault, p.staleLag
+	p.mutex.Unlock()
+
+	switch fault {
+	case FaultConnectionDrop:
+		dropConnection(w)
+		return
+	case FaultTimeout:
+		select {
+		case <-r.Context().Done():
+		case <-p.closed:
+		}
+		return
+	case FaultMalformedJSON:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":`))
+		return
+	case FaultHTTP500:
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("500 Internal Server Error"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		dropConnection(w)
+		return
+	}
+	var request struct {
+		Id     json.RawMessage        `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	_ = json.Unmarshal(body, &request)
+
+	var staleHeight int64
+	if fault == FaultStaleHeight {
+		latest, err := p.latestHeight()
+		if err != nil {
+			dropConnection(w)
+			return
+		}
+		staleHeight = latest - staleLag
+		if request.Method == "abci_query" && paramHeight(request.Params) > staleHeight {
+			writeFutureHeightQuery(w, request.Id)
+			return
+		}
+	}
+
+	resp, err := p.client.Post(p.target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		dropConnection(w)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		dropConnection(w)
+		return
+	}
+
+	switch fault {
+	case FaultStaleHeight:
+		respBody = rewriteLatestHeight(request.Method, respBody, staleHeight)
+	case FaultPartialResponse:
+		// the declared length is kept, the client sees an unexpected EOF
+		w.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+		w.WriteHeader(resp.StatusCode)
+		_, _ = w.Write(respBody[:len(respBody)/2])
+		return
+	}
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
+
+func (p *Proxy) latestHeight() (int64, error) {
+	resp, err := p.client.Post(p.target, "application/json",
+		bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":-1,"method":"status","params":{}}`)))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var status struct {
+		Result struct {
+			SyncInfo struct {
+				LatestBlockHeight string `json:"latest_block_height"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+}
+
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("clienttest: response writer can't be hijacked")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// paramHeight returns the height param of a request, heights are encoded as strings.
+func paramHeight(params map[string]interface{}) int64 {
+	switch h := params["height"].(type) {
+	case string:
+		height, _ := strconv.ParseInt(h, 10, 64)
+		return height
+	case float64:
+		return int64(h)
+	default:
+		return 0
+	}
+}
+
+// writeFutureHeightQuery answers the query the way the sdk does for a height above its
+// latest block.
+func writeFutureHeightQuery(w http.ResponseWriter, id json.RawMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"response":{"code":26,"codespace":"sdk",`+
+		`"log":"cannot query with height in the future; please provide a valid height: invalid height",`+
+		`"height":"0"}}}`, id)
+}
+
+func rewriteLatestHeight(method string, body []byte, height int64) []byte {
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return body
+	}
+	result, ok := response["result"].(map[string]interface{})
+	if !ok {
+		return body
+	}
+	switch method {
+	case "status":
+		if syncInfo, ok := result["sync_info"].(map[string]interface{}); ok {
+			syncInfo["latest_block_height"] = strconv.FormatInt(height, 10)
+		}
+	case "abci_info":
+		if info, ok := result["response"].(map[string]interface{}); ok {
+			info["last_block_height"] = strconv.FormatInt(height, 10)
+		}
+	default:
+		return body
+	}
+	ret, err := json.Marshal(response)
+	if err != nil {
+		return body
+	}
+	return ret
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// Failures are read only here: neutron v2 contractmanager has no MsgResubmitFailure, a failed
+// sudo call can only be retried by the contract itself.
+
+// QueryAddressFailures lists the failed sudo calls recorded for a contract.
+func (c *Client) QueryAddressFailures(contract string, pageReq *query.PageRequest) (*contractmanagerTypes.QueryFailuresResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryAddressFailures(contract, pageReq, 0)
+}
+
+func (c *Client) queryAddressFailures(contract string, pageReq *query.PageRequest, height int64) (*contractmanagerTypes.QueryFailuresResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := contractmanagerTypes.NewQueryClient(clientCtx)
+		return queryClient.AddressFailures(context.Background(), &contractmanagerTypes.QueryFailuresRequest{
+			Address:    contract,
+			Pagination: pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*contractmanagerTypes.QueryFailuresResponse), nil
+}
+
+// GetAddressFailures walks all pages of QueryAddressFailures.
+func (c *Client) GetAddressFailures(contract string) ([]contractmanagerTypes.Failure, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]contractmanagerTypes.Failure, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryAddressFailures(contract, pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, res.Failures...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+func (c *Client) QueryAddressFailure(contract string, failureId uint64) (*contractmanagerTypes.Failure, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := contractmanagerTypes.NewQueryClient(c.Ctx())
+		return queryClient.AddressFailure(context.Background(), &contractmanagerTypes.QueryFailuresRequest{
+			Address:   contract,
+			FailureId: failureId,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := cc.(*contractmanagerTypes.QueryFailuresResponse)
+	if len(res.Failures) == 0 {
+		return nil, fmt.Errorf("failure %d of %s not found", failureId, contract)
+	}
+	return &res.Failures[0], nil
+}
+
+// QueryFailures lists failed sudo calls of all contracts.
+func (c *Client) QueryFailures(pageReq *query.PageRequest) (*contractmanagerTypes.QueryFailuresResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := contractmanagerTypes.NewQueryClient(c.Ctx())
+		return queryClient.Failures(context.Background(), &contractmanagerTypes.QueryFailuresRequest{Pagination: pageReq})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*contractmanagerTypes.QueryFailuresResponse), nil
+}
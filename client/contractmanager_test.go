@@ -0,0 +1,63 @@
+package client
+
+import (
+	"testing"
+
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+)
+
+func TestGetAddressFailures(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	failures := make([]contractmanagerTypes.Failure, 0, 3)
+	for id := uint64(0); id < 3; id++ {
+		failures = append(failures, contractmanagerTypes.Failure{Address: failoverContract, Id: id, Error: "codespace: wasm, code: 5"})
+	}
+	// every page commits a block, the pages must still be read at one height
+	heights := make(map[int64]bool)
+	chain.HandleQuery("/neutron.contractmanager.Query/AddressFailures", func(data []byte, height int64) ([]byte, error) {
+		heights[height] = true
+		chain.CommitBlock()
+		req := contractmanagerTypes.QueryFailuresRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		if req.Address != failoverContract {
+			return (&contractmanagerTypes.QueryFailuresResponse{}).Marshal()
+		}
+		start, end, page := testPage(req.Pagination, len(failures))
+		return (&contractmanagerTypes.QueryFailuresResponse{Failures: failures[start:end], Pagination: page}).Marshal()
+	})
+	chain.HandleQuery("/neutron.contractmanager.Query/AddressFailure", func(data []byte, _ int64) ([]byte, error) {
+		req := contractmanagerTypes.QueryFailuresRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		res := &contractmanagerTypes.QueryFailuresResponse{}
+		if req.Address == failoverContract && req.FailureId < uint64(len(failures)) {
+			res.Failures = failures[req.FailureId : req.FailureId+1]
+		}
+		return res.Marshal()
+	})
+
+	got, err := c.GetAddressFailures(failoverContract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heights) != 1 {
+		t.Fatalf("pages read at heights %v", heights)
+	}
+	if len(got) != 3 || got[2].Id != 2 {
+		t.Fatalf("unexpected failures %+v", got)
+	}
+
+	failure, err := c.QueryAddressFailure(failoverContract, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failure.Id != 1 || failure.Address != failoverContract {
+		t.Fatalf("unexpected failure %+v", failure)
+	}
+	if _, err := c.QueryAddressFailure(failoverContract, 5); err == nil {
+		t.Fatal("expected not found error")
+	}
+}
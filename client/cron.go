@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	cronTypes "github.com/neutron-org/neutron/v2/x/cron/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func (c *Client) QueryCronSchedules(pageReq *query.PageRequest) (*cronTypes.QuerySchedulesResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryCronSchedules(pageReq, 0)
+}
+
+func (c *Client) queryCronSchedules(pageReq *query.PageRequest, height int64) (*cronTypes.QuerySchedulesResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := cronTypes.NewQueryClient(clientCtx)
+		return queryClient.Schedules(context.Background(), &cronTypes.QuerySchedulesRequest{Pagination: pageReq})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*cronTypes.QuerySchedulesResponse), nil
+}
+
+// GetCronSchedules walks all pages of QueryCronSchedules.
+func (c *Client) GetCronSchedules() ([]cronTypes.Schedule, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]cronTypes.Schedule, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryCronSchedules(pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, res.Schedules...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (c *Client) QueryCronSchedule(name string) (*cronTypes.Schedule, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := cronTypes.NewQueryClient(c.Ctx())
+		return queryClient.Schedule(context.Background(), &cronTypes.QueryGetScheduleRequest{Name: name})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cc.(*cronTypes.QueryGetScheduleResponse).Schedule, nil
+}
+
+func (c *Client) QueryCronParams() (*cronTypes.QueryParamsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := cronTypes.NewQueryClient(c.Ctx())
+		return queryClient.Params(context.Background(), &cronTypes.QueryParamsRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*cronTypes.QueryParamsResponse), nil
+}
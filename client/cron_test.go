@@ -0,0 +1,60 @@
+package client
+
+import (
+	"testing"
+
+	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
+	cronTypes "github.com/neutron-org/neutron/v2/x/cron/types"
+)
+
+func TestGetCronSchedules(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	schedules := []cronTypes.Schedule{
+		{Name: "era_update", Period: 100, Msgs: []cronTypes.MsgExecuteContract{{Contract: failoverContract, Msg: `{"era_update":{}}`}}},
+		{Name: "redeem", Period: 50},
+		{Name: "stake", Period: 10, LastExecuteHeight: 7},
+	}
+	// every page commits a block, the pages must still be read at one height
+	heights := make(map[int64]bool)
+	chain.HandleQuery("/neutron.cron.Query/Schedules", func(data []byte, height int64) ([]byte, error) {
+		heights[height] = true
+		chain.CommitBlock()
+		req := cronTypes.QuerySchedulesRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		start, end, page := testPage(req.Pagination, len(schedules))
+		return (&cronTypes.QuerySchedulesResponse{Schedules: schedules[start:end], Pagination: page}).Marshal()
+	})
+	chain.HandleQuery("/neutron.cron.Query/Schedule", func(data []byte, _ int64) ([]byte, error) {
+		req := cronTypes.QueryGetScheduleRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		for _, schedule := range schedules {
+			if schedule.Name == req.Name {
+				return (&cronTypes.QueryGetScheduleResponse{Schedule: schedule}).Marshal()
+			}
+		}
+		return nil, sdkErrors.ErrNotFound.Wrapf("schedule %s not found", req.Name)
+	})
+
+	got, err := c.GetCronSchedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heights) != 1 {
+		t.Fatalf("pages read at heights %v", heights)
+	}
+	if len(got) != 3 || got[2].LastExecuteHeight != 7 {
+		t.Fatalf("unexpected schedules %+v", got)
+	}
+
+	schedule, err := c.QueryCronSchedule("era_update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.Msgs) != 1 || schedule.Msgs[0].Contract != failoverContract {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+}
@@ -27,8 +27,11 @@ import (
 	ibcTransfer "github.com/cosmos/ibc-go/v7/modules/apps/transfer"
 	ibcCore "github.com/cosmos/ibc-go/v7/modules/core"
 	ibcTendermint "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	"github.com/neutron-org/neutron/v2/x/contractmanager"
+	"github.com/neutron-org/neutron/v2/x/cron"
 	"github.com/neutron-org/neutron/v2/x/feerefunder"
 	"github.com/neutron-org/neutron/v2/x/interchainqueries"
+	"github.com/neutron-org/neutron/v2/x/tokenfactory"
 )
 
 // EncodingConfig specifies the concrete encoding types to use for a given app.
@@ -64,6 +67,9 @@ func MakeEncodingConfig() EncodingConfig {
 		upgrade.AppModuleBasic{},
 		interchainqueries.AppModuleBasic{},
 		feerefunder.AppModuleBasic{},
+		cron.AppModuleBasic{},
+		tokenfactory.AppModuleBasic{},
+		contractmanager.AppModuleBasic{},
 
 		ibcTransfer.AppModuleBasic{},
 		ibcCore.AppModuleBasic{},
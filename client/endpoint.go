@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+)
+
+const endpointRetentionRefreshInterval = 10 * time.Minute
+
+// ErrHeightPruned is returned by height pinned queries when no endpoint retains the height.
+var ErrHeightPruned = errors.New("height pruned on all endpoints")
+
+type EndpointKind int
+
+const (
+	EndpointUnknown EndpointKind = iota
+	EndpointArchive
+	EndpointPruned
+)
+
+func (k EndpointKind) String() string {
+	switch k {
+	case EndpointArchive:
+		return "archive"
+	case EndpointPruned:
+		return "pruned"
+	default:
+		return "unknown"
+	}
+}
+
+// EndpointInfo describes the history retained by an endpoint, EarliestHeight is taken from
+// earliest_block_height of /status.
+type EndpointInfo struct {
+	Url            string
+	Kind           EndpointKind
+	EarliestHeight int64
+	// PrunedBelow is raised when the node reports a pruned height, until the next refresh
+	PrunedBelow int64
+	// Manual is set when the kind was given by SetEndpointKind and must not be auto detected
+	Manual    bool
+	CheckedAt time.Time
+}
+
+// Retains reports whether the endpoint is expected to serve queries at height.
+func (e EndpointInfo) Retains(height int64) bool {
+	if height < e.PrunedBelow {
+		return false
+	}
+	switch e.Kind {
+	case EndpointArchive:
+		return true
+	case EndpointPruned:
+		return height >= e.EarliestHeight
+	default:
+		return false
+	}
+}
+
+type endpointRegistry struct {
+	mutex sync.RWMutex
+	infos []EndpointInfo
+}
+
+func newEndpointRegistry(urls []string) *endpointRegistry {
+	infos := make([]EndpointInfo, len(urls))
+	for i, url := range urls {
+		infos[i] = EndpointInfo{Url: url}
+	}
+	return &endpointRegistry{infos: infos}
+}
+
+func (r *endpointRegistry) get(index int) EndpointInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.infos[index]
+}
+
+func (r *endpointRegistry) list() []EndpointInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ret := make([]EndpointInfo, len(r.infos))
+	copy(ret, r.infos)
+	return ret
+}
+
+func (r *endpointRegistry) update(index int, f func(info *EndpointInfo)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f(&r.infos[index])
+}
+
+// Endpoints returns the retention info of all endpoints in config order.
+func (c *Client) Endpoints() []EndpointInfo {
+	return c.endpoints.list()
+}
+
+// SetEndpointKind tags an endpoint by hand, auto detection is disabled for it afterwards.
+func (c *Client) SetEndpointKind(index int, kind EndpointKind, earliestHeight int64) error {
+	if index < 0 || index >= len(c.rpcClientList) {
+		return fmt.Errorf("endpoint index %d out of range", index)
+	}
+	c.endpoints.update(index, func(info *EndpointInfo) {
+		info.Kind = kind
+		info.EarliestHeight = earliestHeight
+		info.Manual = true
+		info.CheckedAt = time.Now()
+	})
+	return nil
+}
+
+// RefreshEndpoints detects the kind of every endpoint from its /status, endpoints that
+// can't be reached keep their previous info.
+func (c *Client) RefreshEndpoints() {
+	for i := range c.rpcClientList {
+		c.refreshEndpoint(i)
+	}
+}
+
+func (c *Client) refreshEndpoint(index int) {
+	if c.endpoints.get(index).Manual {
+		c.endpoints.update(index, func(info *EndpointInfo) {
+			info.PrunedBelow = 0
+			info.CheckedAt = time.Now()
+		})
+		return
+	}
+	status, err := c.rpcClientList[index].Status(context.Background())
+	if err != nil {
+		c.logger.Debug("refresh endpoint failed", "endpoint index", index, "err", err)
+		// don't hit an unreachable endpoint on every query
+		c.endpoints.update(index, func(info *EndpointInfo) {
+			info.CheckedAt = time.Now()
+		})
+		return
+	}
+
+	earliest := status.SyncInfo.EarliestBlockHeight
+	c.endpoints.update(index, func(info *EndpointInfo) {
+		if earliest <= 1 {
+			info.Kind = EndpointArchive
+		} else {
+			info.Kind = EndpointPruned
+		}
+		info.EarliestHeight = earliest
+		// a pruned err may have been transient, trust the fresh status
+		info.PrunedBelow = 0
+		info.CheckedAt = time.Now()
+	})
+}
+
+// markPruned records that index doesn't retain height until the next refresh.
+func (c *Client) markPruned(index int, height int64) {
+	c.endpoints.update(index, func(info *EndpointInfo) {
+		if info.PrunedBelow <= height {
+			info.PrunedBelow = height + 1
+		}
+	})
+}
+
+// heightCandidates returns the endpoints that retain height, starting from the current one,
+// endpoints with unknown kind that were not marked pruned at height are appended last.
+// Stale info is refreshed first.
+func (c *Client) heightCandidates(height int64) []int {
+	for i := range c.rpcClientList {
+		info := c.endpoints.get(i)
+		if time.Since(info.CheckedAt) > endpointRetentionRefreshInterval {
+			c.refreshEndpoint(i)
+		}
+	}
+
+	current := c.CurrentEndpointIndex()
+	retained := make([]int, 0)
+	unknown := make([]int, 0)
+	for j := 0; j < len(c.rpcClientList); j++ {
+		i := (current + j) % len(c.rpcClientList)
+		info := c.endpoints.get(i)
+		switch {
+		case info.Retains(height):
+			retained = append(retained, i)
+		case info.Kind == EndpointUnknown && height >= info.PrunedBelow:
+			unknown = append(unknown, i)
+		}
+	}
+	return append(retained, unknown...)
+}
+
+// retryAtHeight runs f only against endpoints that retain height, without switching the
+// endpoint used by other calls. A zero height means latest and falls back to retry.
+func (c *Client) retryAtHeight(height int64, f func(clientCtx client.Context) (interface{}, error)) (interface{}, error) {
+	if height <= 0 {
+		return c.retry(func() (interface{}, error) {
+			return f(c.Ctx())
+		})
+	}
+
+	var err error
+	for i := 0; i < retryLimit; i++ {
+		candidates := c.heightCandidates(height)
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%w: height %d", ErrHeightPruned, height)
+		}
+
+		var businessErr error
+		for _, index := range candidates {
+			clientCtx := c.abciCtx().WithClient(c.rpcClientList[index]).WithHeight(height)
+			_, span := c.startSpan(context.Background(), "retry attempt",
+				AttrEndpoint.String(c.endpointUrl(index)),
+				AttrHeight.Int64(height))
+			var result interface{}
+			result, err = f(clientCtx)
+			endSpan(span, err)
+			if err == nil {
+				return result, nil
+			}
+			c.metrics.observeFailedAttempt(err)
+			c.logger.Debug("retryAtHeight:",
+				"endpoint index", index,
+				"height", height,
+				"err", err)
+
+			switch {
+			case isHeightPrunedError(err):
+				c.markPruned(index, height)
+			case isConnectionError(err), isHeightAheadError(err):
+				// lagging nodes may catch up on the next round
+			default:
+				businessErr = err
+			}
+		}
+
+		// business err returned by a node that has the height
+		if businessErr != nil {
+			return nil, businessErr
+		}
+		if len(c.heightCandidates(height)) == 0 {
+			return nil, fmt.Errorf("%w: height %d, last err: %s", ErrHeightPruned, height, err)
+		}
+		time.Sleep(waitTime)
+	}
+	return nil, fmt.Errorf("reach retry limit. err: %s", err)
+}
+
+// cometbft rpc err for a block below the retained ones
+var blockPrunedErrRegexp = regexp.MustCompile(`height \d+ is not available, lowest height is \d+`)
+
+// isHeightPrunedError matches the errs of a node that has pruned the queried height: the sdk
+// query ctx and iavl errs for abci queries and the cometbft err for blocks.
+func isHeightPrunedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "failed to load state at height") ||
+		strings.Contains(msg, "version does not exist") ||
+		blockPrunedErrRegexp.MatchString(msg)
+}
+
+// isHeightAheadError reports a query above the latest block of a node that fell behind.
+func isHeightAheadError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "cannot query with height in the future")
+}
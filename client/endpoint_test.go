@@ -0,0 +1,99 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	rpcHttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+)
+
+func TestHeightCandidates(t *testing.T) {
+	urls := []string{"http://127.0.0.1:1", "http://127.0.0.1:2", "http://127.0.0.1:3"}
+	c := &Client{endpoints: newEndpointRegistry(urls), logger: log.NewLog("client", "test")}
+	for _, url := range urls {
+		rClient, err := rpcHttp.New(url, "/websocket")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.rpcClientList = append(c.rpcClientList, rpcClient.Client(rClient))
+	}
+
+	_ = c.SetEndpointKind(0, EndpointPruned, 1000)
+	_ = c.SetEndpointKind(1, EndpointArchive, 1)
+	_ = c.SetEndpointKind(2, EndpointPruned, 500)
+
+	if got := c.heightCandidates(600); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("height 600 candidates: %v", got)
+	}
+	if got := c.heightCandidates(2000); len(got) != 3 {
+		t.Fatalf("height 2000 candidates: %v", got)
+	}
+
+	c.markPruned(1, 600)
+	if got := c.heightCandidates(600); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("height 600 candidates after mark pruned: %v", got)
+	}
+	if got := c.heightCandidates(100); len(got) != 0 {
+		t.Fatalf("height 100 candidates: %v", got)
+	}
+
+	_, err := c.retryAtHeight(100, nil)
+	if !errors.Is(err, ErrHeightPruned) {
+		t.Fatalf("got err %v, want ErrHeightPruned", err)
+	}
+
+	// the pruned mark expires on the next refresh
+	c.endpoints.update(1, func(info *EndpointInfo) { info.CheckedAt = time.Time{} })
+	if got := c.heightCandidates(600); len(got) != 2 || got[0] != 1 {
+		t.Fatalf("height 600 candidates after refresh: %v", got)
+	}
+}
+
+func TestRetryAtHeightUnknownPruned(t *testing.T) {
+	urls := []string{"http://127.0.0.1:1", "http://127.0.0.1:2"}
+	c := &Client{endpoints: newEndpointRegistry(urls), logger: log.NewLog("client", "test")}
+	for _, url := range urls {
+		rClient, err := rpcHttp.New(url, "/websocket")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.rpcClientList = append(c.rpcClientList, rpcClient.Client(rClient))
+	}
+
+	// unreachable endpoints stay unknown and are tried last
+	if got := c.heightCandidates(100); len(got) != 2 || c.Endpoints()[0].Kind != EndpointUnknown {
+		t.Fatalf("unknown endpoints not candidates: %v", got)
+	}
+	start := time.Now()
+	_, err := c.retryAtHeight(100, func(client.Context) (interface{}, error) {
+		return nil, errors.New("failed to load state at height 100; (latest height: 2000)")
+	})
+	if !errors.Is(err, ErrHeightPruned) {
+		t.Fatalf("got err %v, want ErrHeightPruned", err)
+	}
+	if elapsed := time.Since(start); elapsed >= waitTime {
+		t.Fatalf("pruned unknown endpoints retried for %s", elapsed)
+	}
+	if got := c.heightCandidates(200); len(got) != 2 {
+		t.Fatalf("height above the pruned one candidates: %v", got)
+	}
+}
+
+func TestIsHeightPrunedError(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"failed to load state at height 100; version does not exist (latest height: 2000): invalid request": true,
+		"version does not exist":                                                true,
+		"height 100 is not available, lowest height is 1500":                    true,
+		"contract state is not available":                                       false,
+		"the lowest height is stored in config":                                 false,
+		"cannot query with height in the future; please provide a valid height": false,
+	} {
+		if got := isHeightPrunedError(errors.New(msg)); got != want {
+			t.Errorf("isHeightPrunedError(%q) = %v", msg, got)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	sdkMath "cosmossdk.io/math"
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+const wasmAttributeTag = "wasm"
+
+// MsgIndexUnknown is used when events are decoded from TxResponse.Events, which carry no msg index.
+const MsgIndexUnknown = -1
+
+// ContractEvent is a "wasm" or "wasm-<custom>" event emitted by a single contract.
+type ContractEvent struct {
+	Contract string
+	// Type is the full event type, e.g. "wasm" or "wasm-era_update"
+	Type string
+	// Attributes exclude the _contract_address attribute and keep the emitted order
+	Attributes []types.Attribute
+	MsgIndex   int
+}
+
+// CustomType returns the type without the "wasm-" prefix, empty for the plain "wasm" event.
+func (e ContractEvent) CustomType() string {
+	if e.Type == xWasmTypes.WasmModuleEventType {
+		return ""
+	}
+	return strings.TrimPrefix(e.Type, xWasmTypes.CustomContractEventPrefix)
+}
+
+// Attribute returns the value of the first attribute with key.
+func (e ContractEvent) Attribute(key string) (string, bool) {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Unmarshal fills the fields of the struct pointed to by v from the event attributes.
+// Fields are matched by the `wasm:"key"` tag, or by field name when the tag is absent;
+// `wasm:"-"` skips a field and attributes missing from the event leave the field untouched.
+// Supported field types are strings, bools, ints, uints, math.Int, types.Coins and
+// anything implementing encoding.TextUnmarshaler.
+func (e ContractEvent) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer to struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Name
+		if tag, ok := field.Tag.Lookup(wasmAttributeTag); ok {
+			if tag == "-" {
+				continue
+			}
+			key = tag
+		}
+		value, ok := e.Attribute(key)
+		if !ok {
+			continue
+		}
+		if err := setAttributeValue(rv.Field(i), value); err != nil {
+			return fmt.Errorf("attribute %s of event %s: %s", key, e.Type, err)
+		}
+	}
+	return nil
+}
+
+var (
+	sdkIntType          = reflect.TypeOf(sdkMath.Int{})
+	coinsType           = reflect.TypeOf(types.Coins{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func setAttributeValue(field reflect.Value, value string) error {
+	switch field.Type() {
+	case sdkIntType:
+		amount, ok := types.NewIntFromString(value)
+		if !ok {
+			return fmt.Errorf("invalid int: %s", value)
+		}
+		field.Set(reflect.ValueOf(amount))
+		return nil
+	case coinsType:
+		coins, err := types.ParseCoinsNormalized(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(coins))
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func isContractEventType(eventType string) bool {
+	return eventType == xWasmTypes.WasmModuleEventType || strings.HasPrefix(eventType, xWasmTypes.CustomContractEventPrefix)
+}
+
+// DecodeContractEvents extracts contract events from a tx. Logs are preferred since they carry
+// the msg index; TxResponse.Events is used when the node returned no logs.
+func DecodeContractEvents(tx *types.TxResponse) []ContractEvent {
+	events := make([]ContractEvent, 0)
+	if len(tx.Logs) > 0 {
+		for _, msgLog := range tx.Logs {
+			for _, event := range msgLog.Events {
+				if !isContractEventType(event.Type) {
+					continue
+				}
+				events = append(events, splitContractEvent(event.Type, event.Attributes, int(msgLog.MsgIndex))...)
+			}
+		}
+		return events
+	}
+
+	for _, event := range tx.Events {
+		if !isContractEventType(event.Type) {
+			continue
+		}
+		events = append(events, splitContractEvent(event.Type, abciAttributes(event.Attributes), MsgIndexUnknown)...)
+	}
+	return events
+}
+
+// splitContractEvent splits an event on _contract_address, logs merge all events of the
+// same type within a msg into one so a single "wasm" entry may hold several contracts.
+func splitContractEvent(eventType string, attrs []types.Attribute, msgIndex int) []ContractEvent {
+	events := make([]ContractEvent, 0)
+	for _, attr := range attrs {
+		if attr.Key == xWasmTypes.AttributeKeyContractAddr {
+			events = append(events, ContractEvent{
+				Contract:   attr.Value,
+				Type:       eventType,
+				Attributes: make([]types.Attribute, 0),
+				MsgIndex:   msgIndex,
+			})
+			continue
+		}
+		// attributes before any contract address are not emitted by a contract
+		if len(events) == 0 {
+			continue
+		}
+		last := len(events) - 1
+		events[last].Attributes = append(events[last].Attributes, attr)
+	}
+	return events
+}
+
+func abciAttributes(attrs []abci.EventAttribute) []types.Attribute {
+	ret := make([]types.Attribute, len(attrs))
+	for i, attr := range attrs {
+		ret[i] = types.Attribute{Key: attr.Key, Value: attr.Value}
+	}
+	return ret
+}
+
+// MatchEvent reports whether a decoded contract event passes the filter.
+func (f EventFilter) MatchEvent(e ContractEvent) bool {
+	if len(f.EventType) != 0 && e.Type != f.EventType {
+		return false
+	}
+	if len(f.ContractAddress) != 0 && e.Contract != f.ContractAddress {
+		return false
+	}
+	return true
+}
+
+// FilterContractEvents returns the events that pass the filter.
+func FilterContractEvents(events []ContractEvent, filter EventFilter) []ContractEvent {
+	ret := make([]ContractEvent, 0)
+	for _, e := range events {
+		if filter.MatchEvent(e) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
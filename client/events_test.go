@@ -0,0 +1,64 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestDecodeContractEvents(t *testing.T) {
+	tx := &types.TxResponse{
+		Logs: types.ABCIMessageLogs{
+			{
+				MsgIndex: 1,
+				Events: types.StringEvents{
+					{Type: "message", Attributes: []types.Attribute{{Key: "sender", Value: "neutron1sender"}}},
+					{Type: "wasm", Attributes: []types.Attribute{
+						{Key: "_contract_address", Value: "neutron1pool"},
+						{Key: "action", Value: "era_update"},
+						{Key: "_contract_address", Value: "neutron1token"},
+						{Key: "action", Value: "mint"},
+					}},
+					{Type: "wasm-era_update", Attributes: []types.Attribute{
+						{Key: "_contract_address", Value: "neutron1pool"},
+						{Key: "era", Value: "12"},
+						{Key: "rate", Value: "1000000"},
+						{Key: "finished", Value: "true"},
+					}},
+				},
+			},
+		},
+	}
+
+	events := DecodeContractEvents(tx)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if events[1].Contract != "neutron1token" || events[1].MsgIndex != 1 {
+		t.Fatalf("unexpected second event: %+v", events[1])
+	}
+	if action, _ := events[1].Attribute("action"); action != "mint" {
+		t.Fatalf("got action %s, want mint", action)
+	}
+	if events[0].CustomType() != "" {
+		t.Fatalf("plain wasm event has custom type %q", events[0].CustomType())
+	}
+
+	eraEvents := FilterContractEvents(events, EventFilter{EventType: "wasm-era_update", ContractAddress: "neutron1pool"})
+	if len(eraEvents) != 1 || eraEvents[0].CustomType() != "era_update" {
+		t.Fatalf("unexpected filtered events: %+v", eraEvents)
+	}
+
+	var eraUpdate struct {
+		Era      uint64    `wasm:"era"`
+		Rate     types.Int `wasm:"rate"`
+		Finished bool      `wasm:"finished"`
+		Missing  string    `wasm:"missing"`
+	}
+	if err := eraEvents[0].Unmarshal(&eraUpdate); err != nil {
+		t.Fatal(err)
+	}
+	if eraUpdate.Era != 12 || !eraUpdate.Rate.Equal(types.NewInt(1000000)) || !eraUpdate.Finished || eraUpdate.Missing != "" {
+		t.Fatalf("unexpected unmarshal result: %+v", eraUpdate)
+	}
+}
@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	rpcHttp "github.com/cometbft/cometbft/rpc/client/http"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+)
+
+const failoverContract = "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq"
+
+var transportFaults = []clienttest.Fault{
+	clienttest.FaultConnectionRefused,
+	clienttest.FaultConnectionDrop,
+	clienttest.FaultTimeout,
+	clienttest.FaultMalformedJSON,
+	clienttest.FaultHTTP500,
+	clienttest.FaultPartialResponse,
+}
+
+// newFailoverClient returns a client whose first endpoint is a proxy of chain and whose
+// second endpoint is chain itself.
+func newFailoverClient(t *testing.T) (*Client, *clienttest.Proxy, *clienttest.Chain) {
+	chain := clienttest.NewChain("neutron-test-1")
+	t.Cleanup(chain.Close)
+	proxy := clienttest.NewProxy(chain.URL())
+	t.Cleanup(proxy.Close)
+
+	kr, account := newTestAccount(t)
+	chain.SetAccount(account)
+	if err := chain.SetSmartQueryResponse(failoverContract, []byte(`{"era":{}}`), []byte(`{"era":12}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewClient(kr, "relayer", "0.005untrn", "neutron", []string{proxy.URL(), chain.URL()},
+		log.NewLog("client", "test"), WithRPCTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, proxy, chain
+}
+
+func TestFaultClassification(t *testing.T) {
+	chain := clienttest.NewChain("neutron-test-1")
+	defer chain.Close()
+	proxy := clienttest.NewProxy(chain.URL())
+	defer proxy.Close()
+
+	rClient, err := rpcHttp.NewWithClient(proxy.URL(), "/websocket", &http.Client{Timeout: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rClient.Status(context.Background()); err != nil {
+		t.Fatalf("healthy proxy: %s", err)
+	}
+
+	for _, fault := range transportFaults {
+		if err := proxy.SetFault(fault); err != nil {
+			t.Fatal(err)
+		}
+		_, err := rClient.Status(context.Background())
+		if err == nil {
+			t.Errorf("%s: got no err", fault)
+			continue
+		}
+		if !isConnectionError(err) {
+			t.Errorf("%s: err %q not classified as connection err", fault, err)
+		}
+	}
+
+	// a lagging node answers, but its height is behind
+	if err := proxy.SetFault(clienttest.FaultStaleHeight); err != nil {
+		t.Fatal(err)
+	}
+	chain.CommitBlock()
+	status, err := rClient.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.SyncInfo.LatestBlockHeight != chain.Height()-1 {
+		t.Fatalf("stale height %d, chain height %d", status.SyncInfo.LatestBlockHeight, chain.Height())
+	}
+}
+
+func TestFailover(t *testing.T) {
+	for _, fault := range transportFaults {
+		t.Run(fault.String(), func(t *testing.T) {
+			c, proxy, _ := newFailoverClient(t)
+			if err := proxy.SetFault(fault); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(res.Data) != `{"era":12}` {
+				t.Fatalf("unexpected response %s", res.Data)
+			}
+			if c.CurrentEndpointIndex() != 1 {
+				t.Fatalf("endpoint index %d, want failover to 1", c.CurrentEndpointIndex())
+			}
+		})
+	}
+}
+
+func TestFailoverStaleHeight(t *testing.T) {
+	c, proxy, chain := newFailoverClient(t)
+	chain.CommitBlock()
+	if err := proxy.SetFault(clienttest.FaultStaleHeight); err != nil {
+		t.Fatal(err)
+	}
+
+	// latest queries are still served by the lagging node
+	if _, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatalf("endpoint index %d, want 0", c.CurrentEndpointIndex())
+	}
+
+	res, err := c.QuerySmartContractStateWithHeight(failoverContract, []byte(`{"era":{}}`), chain.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"era":12}` {
+		t.Fatalf("unexpected response %s", res.Data)
+	}
+	// pinned queries don't move the endpoint used by other calls
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatalf("endpoint index %d, want 0", c.CurrentEndpointIndex())
+	}
+}
+
+func TestFailoverRecovery(t *testing.T) {
+	c, proxy, _ := newFailoverClient(t)
+	if err := proxy.SetFault(clienttest.FaultConnectionRefused); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the proxy comes back on the same address
+	if err := proxy.SetFault(clienttest.FaultNone); err != nil {
+		t.Fatal(err)
+	}
+	c.ChangeEndpoint()
+	if _, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatalf("endpoint index %d, want 0", c.CurrentEndpointIndex())
+	}
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	c := &Client{
+		rpcClientList: []rpcClient.Client{nil, nil},
+		endpoints:     newEndpointRegistry([]string{"http://a", "http://b"}),
+		logger:        log.NewLog("client", "test"),
+	}
+
+	// a cancelled ctx fails before any attempt
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	_, err := c.retryWithContext(ctx, func(context.Context) (interface{}, error) {
+		calls++
+		return "ok", nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 0 {
+		t.Fatalf("got err %v after %d calls", err, calls)
+	}
+
+	// the caller's cancellation is not an endpoint failure
+	ctx, cancel = context.WithCancel(context.Background())
+	_, err = c.retryWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		cancel()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err %v", err)
+	}
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatal("endpoint changed on caller cancellation")
+	}
+
+	// cancellation stops the wait between connection err retries
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.retryWithContext(ctx, func(context.Context) (interface{}, error) {
+		return nil, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got err %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= waitTime {
+		t.Fatalf("retry kept waiting %s after the deadline", elapsed)
+	}
+}
+
+func TestWaitTxIncludedFailover(t *testing.T) {
+	c, proxy, _ := newFailoverClient(t)
+	txHash, err := c.SendContractExecuteMsg(failoverContract, []byte(`{"era_update":{}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.SetFault(clienttest.FaultConnectionRefused); err != nil {
+		t.Fatal(err)
+	}
+
+	// the poll after the refused one goes to the next endpoint
+	res, err := c.WaitTxIncluded(txHash, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TxHash != txHash {
+		t.Fatalf("unexpected tx %s", res.TxHash)
+	}
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatalf("endpoint index %d, want 0", c.CurrentEndpointIndex())
+	}
+
+	// an unknown tx times out at the deadline without moving the endpoint
+	start := time.Now()
+	_, err = c.WaitTxIncluded(strings.Repeat("AB", 32), time.Second)
+	if !errors.Is(err, ErrTxInclusionPending) {
+		t.Fatalf("got err %v, want ErrTxInclusionPending", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second+waitTime/2 {
+		t.Fatalf("waited %s for a 1s timeout", elapsed)
+	}
+	if c.CurrentEndpointIndex() != 0 {
+		t.Fatalf("endpoint index %d, want 0", c.CurrentEndpointIndex())
+	}
+}
+
+func TestConstructAndSignTxDeadline(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	// the account query hangs until the test ends
+	chain.HandleQuery(clienttest.AccountQueryPath, func([]byte, int64) ([]byte, error) {
+		<-release
+		return nil, errors.New("released")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.ConstructAndSignTxWithContext(ctx, "", &xBankTypes.MsgSend{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got err %v, want the caller deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed >= waitTime {
+		t.Fatalf("account fetch kept going %s after the deadline", elapsed)
+	}
+}
@@ -0,0 +1,338 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	icaTypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	channelTypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+const defaultFailureWatchInterval = time.Minute
+
+var ErrFailureWatcherStarted = errors.New("failure watcher already started")
+
+// Sudo callback kinds of a FailureEvent
+const (
+	SudoCallbackResponse = "response"
+	SudoCallbackError    = "error"
+	SudoCallbackTimeout  = "timeout"
+	SudoCallbackUnknown  = "unknown"
+)
+
+// FailureStore persists the failure ids already reported for each contract.
+type FailureStore interface {
+	LoadSeenFailures(contract string) ([]uint64, error)
+	StoreSeenFailures(contract string, ids []uint64) error
+}
+
+// FailureEvent is a failed sudo call of a watched contract with its original packet decoded.
+type FailureEvent struct {
+	Contract string
+	Failure  contractmanagerTypes.Failure
+	// Kind is one of SudoCallbackResponse, SudoCallbackError, SudoCallbackTimeout or SudoCallbackUnknown
+	// when the payload isn't an ibc callback, e.g. a failed interchain query result
+	Kind   string
+	Packet *channelTypes.Packet
+	// Ack is the acknowledgement result data of a response callback
+	Ack []byte
+	// AckError is the error details of an error callback
+	AckError string
+	// IcaMsgs are the msgs sent through an interchain account packet
+	IcaMsgs []DecodedMsg
+	// Transfer is the payload of an ics20 transfer packet
+	Transfer *ibcTransferTypes.FungibleTokenPacketData
+	// DecodeErr is set when the payload could not be fully decoded, the event is still emitted
+	DecodeErr error
+}
+
+// FailureHandler is called once for every newly seen failure.
+type FailureHandler func(event FailureEvent)
+
+// FailureWatcher periodically lists the contractmanager failures of a set of contracts and
+// reports those not seen before. Seen ids are persisted after the handler ran for all new
+// failures of a contract, so after a crash a failure may be reported again but never missed.
+type FailureWatcher struct {
+	client    ContractClient
+	store     FailureStore
+	contracts []string
+	handler   FailureHandler
+	interval  time.Duration
+	logger    log.Logger
+
+	mutex   sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+}
+
+func NewFailureWatcher(c ContractClient, store FailureStore, contracts []string, handler FailureHandler, interval time.Duration, logger log.Logger) (*FailureWatcher, error) {
+	if isNil(c) {
+		return nil, fmt.Errorf("client is nil")
+	}
+	if isNil(store) {
+		return nil, fmt.Errorf("failure store is nil")
+	}
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("no contracts to watch")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("failure handler is nil")
+	}
+	if isNil(logger) {
+		return nil, fmt.Errorf("logger is nil")
+	}
+	if interval <= 0 {
+		interval = defaultFailureWatchInterval
+	}
+
+	return &FailureWatcher{
+		client:    c,
+		store:     store,
+		contracts: contracts,
+		handler:   handler,
+		interval:  interval,
+		logger:    logger,
+	}, nil
+}
+
+func (w *FailureWatcher) Start() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.started {
+		return ErrFailureWatcherStarted
+	}
+	w.started = true
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go w.run()
+	return nil
+}
+
+// Stop signals the watch loop to exit and waits for the current round to finish.
+func (w *FailureWatcher) Stop() {
+	w.mutex.Lock()
+	if !w.started {
+		w.mutex.Unlock()
+		return
+	}
+	w.started = false
+	close(w.stop)
+	done := w.done
+	w.mutex.Unlock()
+
+	<-done
+}
+
+func (w *FailureWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.CheckOnce(); err != nil {
+			w.logger.Warn("failure watcher round failed", "err", err)
+		}
+		select {
+		case <-w.stop:
+			w.logger.Info("failure watcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce checks every contract once and returns the number of new failures. A contract
+// that fails to be checked doesn't stop the others, the first error is returned.
+func (w *FailureWatcher) CheckOnce() (int, error) {
+	total := 0
+	var firstErr error
+	for _, contract := range w.contracts {
+		n, err := w.checkContract(contract)
+		total += n
+		if err != nil {
+			w.logger.Warn("check contract failures failed", "contract", contract, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return total, firstErr
+}
+
+func (w *FailureWatcher) checkContract(contract string) (int, error) {
+	seenIds, err := w.store.LoadSeenFailures(contract)
+	if err != nil {
+		return 0, fmt.Errorf("load seen failures err: %w", err)
+	}
+	seen := make(map[uint64]bool, len(seenIds))
+	for _, id := range seenIds {
+		seen[id] = true
+	}
+
+	failures, err := w.client.GetAddressFailures(contract)
+	if err != nil {
+		return 0, err
+	}
+
+	current := make([]uint64, 0, len(failures))
+	newCount := 0
+	for _, failure := range failures {
+		current = append(current, failure.Id)
+		if seen[failure.Id] {
+			delete(seen, failure.Id)
+			continue
+		}
+		newCount++
+		event := w.client.DecodeFailure(contract, failure)
+		w.logger.Warn("new contract sudo failure", "contract", contract, "id", failure.Id,
+			"kind", event.Kind, "error", failure.Error)
+		if event.DecodeErr != nil {
+			w.logger.Warn("decode sudo failure payload failed", "contract", contract, "id", failure.Id, "err", event.DecodeErr)
+		}
+		w.handler(event)
+	}
+	for id := range seen {
+		w.logger.Info("contract sudo failure cleared", "contract", contract, "id", id)
+	}
+
+	// only current ids are kept, so cleared failures don't grow the store forever
+	if newCount > 0 || len(seen) > 0 {
+		if err := w.store.StoreSeenFailures(contract, current); err != nil {
+			return newCount, fmt.Errorf("store seen failures err: %w", err)
+		}
+	}
+	return newCount, nil
+}
+
+// DecodeFailure decodes the sudo payload of a failure, decode errors are kept in DecodeErr.
+func (c *Client) DecodeFailure(contract string, failure contractmanagerTypes.Failure) FailureEvent {
+	event := FailureEvent{
+		Contract: contract,
+		Failure:  failure,
+		Kind:     SudoCallbackUnknown,
+	}
+
+	callback := contractmanagerTypes.MessageSudoCallback{}
+	if err := json.Unmarshal(failure.SudoPayload, &callback); err != nil {
+		event.DecodeErr = fmt.Errorf("unmarshal sudo payload err: %w", err)
+		return event
+	}
+	switch {
+	case callback.Response != nil:
+		event.Kind = SudoCallbackResponse
+		event.Packet = &callback.Response.Request
+		event.Ack = callback.Response.Data
+	case callback.Error != nil:
+		event.Kind = SudoCallbackError
+		event.Packet = &callback.Error.Request
+		event.AckError = callback.Error.Details
+	case callback.Timeout != nil:
+		event.Kind = SudoCallbackTimeout
+		event.Packet = &callback.Timeout.Request
+	default:
+		return event
+	}
+
+	event.DecodeErr = c.decodeFailurePacket(&event)
+	return event
+}
+
+func (c *Client) decodeFailurePacket(event *FailureEvent) error {
+	switch {
+	case strings.HasPrefix(event.Packet.SourcePort, icaTypes.ControllerPortPrefix):
+		packetData := icaTypes.InterchainAccountPacketData{}
+		if err := icaTypes.ModuleCdc.UnmarshalJSON(event.Packet.Data, &packetData); err != nil {
+			return fmt.Errorf("unmarshal ica packet data err: %w", err)
+		}
+
+		done := core.UseSdkConfigContext(c.GetAccountPrefix())
+		defer done()
+		msgs, err := icaTypes.DeserializeCosmosTx(c.Ctx().Codec, packetData.Data)
+		if err != nil {
+			return fmt.Errorf("deserialize ica tx err: %w", err)
+		}
+		event.IcaMsgs, err = decodeMsgs(msgs)
+		return err
+	case event.Packet.SourcePort == ibcTransferTypes.PortID:
+		transfer := ibcTransferTypes.FungibleTokenPacketData{}
+		if err := ibcTransferTypes.ModuleCdc.UnmarshalJSON(event.Packet.Data, &transfer); err != nil {
+			return fmt.Errorf("unmarshal transfer packet data err: %w", err)
+		}
+		event.Transfer = &transfer
+	}
+	return nil
+}
+
+var _ FailureStore = &FileFailureStore{}
+
+// FileFailureStore keeps seen failure ids of all contracts in one json file.
+type FileFailureStore struct {
+	mutex    sync.Mutex
+	fullPath string
+}
+
+func NewFileFailureStore(fullPath string) *FileFailureStore {
+	return &FileFailureStore{fullPath: fullPath}
+}
+
+func (s *FileFailureStore) LoadSeenFailures(contract string) ([]uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return all[contract], nil
+}
+
+func (s *FileFailureStore) StoreSeenFailures(contract string, ids []uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	sorted := append([]uint64{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	all[contract] = sorted
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.fullPath), os.ModePerm); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(s.fullPath, data, 0600)
+}
+
+func (s *FileFailureStore) load() (map[string][]uint64, error) {
+	all := make(map[string][]uint64)
+	data, err := os.ReadFile(s.fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("unmarshal %s err: %w", s.fullPath, err)
+	}
+	return all, nil
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	channelTypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+)
+
+func TestDecodeFailure(t *testing.T) {
+	transfer := ibcTransferTypes.NewFungibleTokenPacketData("untrn", "100", "neutron1pool", "cosmos1receiver", "")
+	callback := contractmanagerTypes.MessageSudoCallback{
+		Timeout: &contractmanagerTypes.TimeoutPayload{
+			Request: channelTypes.Packet{
+				Sequence:   7,
+				SourcePort: ibcTransferTypes.PortID,
+				Data:       transfer.GetBytes(),
+			},
+		},
+	}
+	payload, err := json.Marshal(callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{}
+	event := c.DecodeFailure("neutron1pool", contractmanagerTypes.Failure{Address: "neutron1pool", Id: 3, SudoPayload: payload})
+	if event.DecodeErr != nil {
+		t.Fatal(event.DecodeErr)
+	}
+	if event.Kind != SudoCallbackTimeout || event.Packet.Sequence != 7 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if event.Transfer == nil || event.Transfer.Amount != "100" || event.Transfer.Receiver != "cosmos1receiver" {
+		t.Fatalf("unexpected transfer %+v", event.Transfer)
+	}
+
+	event = c.DecodeFailure("neutron1pool", contractmanagerTypes.Failure{SudoPayload: []byte(`{"kv_query_result":{"query_id":1}}`)})
+	if event.Kind != SudoCallbackUnknown || event.DecodeErr != nil {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestFileFailureStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.json")
+	store := NewFileFailureStore(path)
+
+	ids, err := store.LoadSeenFailures("neutron1pool")
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("load empty store: %v %v", ids, err)
+	}
+	if err := store.StoreSeenFailures("neutron1pool", []uint64{5, 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err = NewFileFailureStore(path).LoadSeenFailures("neutron1pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 5 {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+
+	// stores of the same file don't share a temp file
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- NewFileFailureStore(path).StoreSeenFailures("neutron1pool", []uint64{uint64(i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ids, err := store.LoadSeenFailures("neutron1pool"); err != nil || len(ids) != 1 {
+		t.Fatalf("unexpected ids %v %v", ids, err)
+	}
+	files, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("%d files left in the store dir", len(files))
+	}
+}
+
+func TestNewFailureWatcherTypedNil(t *testing.T) {
+	store := NewFileFailureStore(filepath.Join(t.TempDir(), "failures.json"))
+	handler := func(FailureEvent) {}
+	logger := log.NewLog("client", "failure")
+	if _, err := NewFailureWatcher((*Client)(nil), store, []string{"neutron1pool"}, handler, 0, logger); err == nil {
+		t.Fatal("nil client accepted")
+	}
+	if _, err := NewFailureWatcher(&Client{}, (*FileFailureStore)(nil), []string{"neutron1pool"}, handler, 0, logger); err == nil {
+		t.Fatal("nil store accepted")
+	}
+}
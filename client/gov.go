@@ -0,0 +1,333 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	xGovTypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	xGovTypesV1beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// DecodedProposal is a gov proposal with its msgs decoded, legacy content proposals wrapped
+// in MsgExecLegacyContent are also exposed as v1beta1.Content.
+type DecodedProposal struct {
+	Proposal       xGovTypes.Proposal
+	Msgs           []DecodedMsg
+	LegacyContents []xGovTypesV1beta1.Content
+	// Err is set by GetProposals when the msgs could not be decoded, Msgs and
+	// LegacyContents are empty then
+	Err error
+}
+
+// status ProposalStatus_PROPOSAL_STATUS_UNSPECIFIED lists proposals of all status
+func (c *Client) QueryProposals(status xGovTypes.ProposalStatus, pageReq *query.PageRequest) (*xGovTypes.QueryProposalsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryProposals(status, pageReq, 0)
+}
+
+func (c *Client) queryProposals(status xGovTypes.ProposalStatus, pageReq *query.PageRequest, height int64) (*xGovTypes.QueryProposalsResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xGovTypes.NewQueryClient(clientCtx)
+		return queryClient.Proposals(context.Background(), &xGovTypes.QueryProposalsRequest{
+			ProposalStatus: status,
+			Pagination:     pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xGovTypes.QueryProposalsResponse), nil
+}
+
+// GetProposals walks all pages of QueryProposals and decodes every proposal. A proposal
+// whose msgs can not be decoded is still returned, with the decode error in Err.
+func (c *Client) GetProposals(status xGovTypes.ProposalStatus) ([]*DecodedProposal, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]*DecodedProposal, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryProposals(status, pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, decodeProposals(c.Ctx().InterfaceRegistry, res.Proposals)...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+func (c *Client) QueryProposal(proposalId uint64) (*DecodedProposal, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xGovTypes.NewQueryClient(c.Ctx())
+		return queryClient.Proposal(context.Background(), &xGovTypes.QueryProposalRequest{ProposalId: proposalId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeProposal(c.Ctx().InterfaceRegistry, cc.(*xGovTypes.QueryProposalResponse).Proposal)
+}
+
+// QueryTallyResult returns the current tally of a proposal in voting period, or the final
+// tally of a finished one.
+func (c *Client) QueryTallyResult(proposalId uint64) (*xGovTypes.TallyResult, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xGovTypes.NewQueryClient(c.Ctx())
+		return queryClient.TallyResult(context.Background(), &xGovTypes.QueryTallyResultRequest{ProposalId: proposalId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xGovTypes.QueryTallyResultResponse).Tally, nil
+}
+
+// decodeProposals decodes a page of proposals, keeping the ones that fail to decode with Err set.
+func decodeProposals(unpacker codecTypes.AnyUnpacker, proposals []*xGovTypes.Proposal) []*DecodedProposal {
+	decodedProposals := make([]*DecodedProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		if proposal == nil {
+			continue
+		}
+		decoded, err := decodeProposal(unpacker, proposal)
+		if err != nil {
+			decoded = &DecodedProposal{
+				Proposal:       *proposal,
+				Msgs:           make([]DecodedMsg, 0),
+				LegacyContents: make([]xGovTypesV1beta1.Content, 0),
+				Err:            err,
+			}
+		}
+		decodedProposals = append(decodedProposals, decoded)
+	}
+	return decodedProposals
+}
+
+// decodeProposal unpacks the msgs of proposal with unpacker first, the gov query responses
+// do not unpack them.
+func decodeProposal(unpacker codecTypes.AnyUnpacker, proposal *xGovTypes.Proposal) (*DecodedProposal, error) {
+	if proposal == nil {
+		return nil, fmt.Errorf("proposal is nil")
+	}
+	if err := proposal.UnpackInterfaces(unpacker); err != nil {
+		return nil, fmt.Errorf("proposal %d unpack msgs err: %s", proposal.Id, err)
+	}
+	msgs, err := proposal.GetMsgs()
+	if err != nil {
+		return nil, fmt.Errorf("proposal %d get msgs err: %s", proposal.Id, err)
+	}
+	decodedMsgs, err := decodeMsgs(msgs)
+	if err != nil {
+		return nil, fmt.Errorf("proposal %d decode msgs err: %s", proposal.Id, err)
+	}
+
+	decoded := &DecodedProposal{
+		Proposal:       *proposal,
+		Msgs:           decodedMsgs,
+		LegacyContents: make([]xGovTypesV1beta1.Content, 0),
+	}
+	for _, msg := range msgs {
+		legacyMsg, ok := msg.(*xGovTypes.MsgExecLegacyContent)
+		if !ok {
+			continue
+		}
+		content, err := xGovTypes.LegacyContentFromMessage(legacyMsg)
+		if err != nil {
+			return nil, fmt.Errorf("proposal %d decode legacy content err: %s", proposal.Id, err)
+		}
+		decoded.LegacyContents = append(decoded.LegacyContents, content)
+	}
+	return decoded, nil
+}
+
+// Vote votes on a gov proposal with the from account.
+func (c *Client) Vote(proposalId uint64, option xGovTypes.VoteOption, metadata string) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xGovTypes.NewMsgVote(c.Ctx().GetFromAddress(), proposalId, option, metadata)
+	done()
+
+	txBts, err := c.ConstructAndSignTx(msg)
+	if err != nil {
+		return "", err
+	}
+	return c.BroadcastTx(txBts)
+}
+
+// Neutron has no permissionless x/gov, proposals live in the cw-dao proposal module contracts
+// and are read and voted on through smart queries and execute msgs.
+
+const daoProposalPageLimit = 30
+
+type DaoProposalModule struct {
+	Address string `json:"address"`
+	Prefix  string `json:"prefix"`
+	Status  string `json:"status"`
+}
+
+type DaoProposal struct {
+	Id       uint64          `json:"id"`
+	Proposal DaoProposalInfo `json:"proposal"`
+}
+
+type DaoProposalInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Proposer    string `json:"proposer"`
+	StartHeight uint64 `json:"start_height"`
+	// Expiration is a cw-utils Expiration: {"at_height":..}, {"at_time":..} or {"never":{}}
+	Expiration json.RawMessage `json:"expiration"`
+	// Msgs are CosmosMsg json, e.g. {"wasm":{"migrate":{..}}} or {"custom":{"param_change":{..}}}
+	Msgs       []json.RawMessage `json:"msgs"`
+	Status     string            `json:"status"`
+	TotalPower types.Int         `json:"total_power"`
+	Votes      DaoVotes          `json:"votes"`
+}
+
+type DaoVotes struct {
+	Yes     types.Int `json:"yes"`
+	No      types.Int `json:"no"`
+	Abstain types.Int `json:"abstain"`
+}
+
+// DaoProposal status values
+const (
+	DaoProposalStatusOpen            = "open"
+	DaoProposalStatusRejected        = "rejected"
+	DaoProposalStatusPassed          = "passed"
+	DaoProposalStatusExecuted        = "executed"
+	DaoProposalStatusClosed          = "closed"
+	DaoProposalStatusExecutionFailed = "execution_failed"
+)
+
+// DaoVote options
+const (
+	DaoVoteYes     = "yes"
+	DaoVoteNo      = "no"
+	DaoVoteAbstain = "abstain"
+)
+
+// MsgKind returns the top level keys of a proposal msg, e.g. ["wasm", "migrate"].
+func MsgKind(msg json.RawMessage) ([]string, error) {
+	kinds := make([]string, 0, 2)
+	for len(kinds) < 2 {
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(msg, &fields); err != nil || len(fields) != 1 {
+			break
+		}
+		for key, value := range fields {
+			kinds = append(kinds, key)
+			msg = value
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("unknown msg: %s", string(msg))
+	}
+	return kinds, nil
+}
+
+func (c *Client) QueryDaoProposalModules(daoCore string) ([]DaoProposalModule, error) {
+	res, err := c.QuerySmartContractState(daoCore, []byte(`{"proposal_modules":{}}`))
+	if err != nil {
+		return nil, err
+	}
+	modules := make([]DaoProposalModule, 0)
+	if err := json.Unmarshal(res.Data, &modules); err != nil {
+		return nil, fmt.Errorf("unmarshal proposal modules of %s err: %s", daoCore, err)
+	}
+	return modules, nil
+}
+
+func (c *Client) QueryDaoProposal(proposalModule string, proposalId uint64) (*DaoProposal, error) {
+	req := fmt.Sprintf(`{"proposal":{"proposal_id":%d}}`, proposalId)
+	res, err := c.QuerySmartContractState(proposalModule, []byte(req))
+	if err != nil {
+		return nil, err
+	}
+	proposal := new(DaoProposal)
+	if err := json.Unmarshal(res.Data, proposal); err != nil {
+		return nil, fmt.Errorf("unmarshal proposal %d of %s err: %s", proposalId, proposalModule, err)
+	}
+	return proposal, nil
+}
+
+// QueryDaoProposals lists proposals with id greater than startAfter.
+func (c *Client) QueryDaoProposals(proposalModule string, startAfter uint64, limit uint64) ([]DaoProposal, error) {
+	return c.queryDaoProposals(proposalModule, startAfter, limit, 0)
+}
+
+func (c *Client) queryDaoProposals(proposalModule string, startAfter uint64, limit uint64, height int64) ([]DaoProposal, error) {
+	req := fmt.Sprintf(`{"list_proposals":{"limit":%d}}`, limit)
+	if startAfter > 0 {
+		req = fmt.Sprintf(`{"list_proposals":{"start_after":%d,"limit":%d}}`, startAfter, limit)
+	}
+	res, err := c.QuerySmartContractStateWithHeight(proposalModule, []byte(req), height)
+	if err != nil {
+		return nil, err
+	}
+	ret := struct {
+		Proposals []DaoProposal `json:"proposals"`
+	}{}
+	if err := json.Unmarshal(res.Data, &ret); err != nil {
+		return nil, fmt.Errorf("unmarshal proposals of %s err: %s", proposalModule, err)
+	}
+	return ret.Proposals, nil
+}
+
+// GetDaoProposals walks all proposals of a proposal module, status empty matches all.
+func (c *Client) GetDaoProposals(proposalModule string, status string) ([]DaoProposal, error) {
+	height, err := c.pinHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]DaoProposal, 0)
+	startAfter := uint64(0)
+	for {
+		page, err := c.queryDaoProposals(proposalModule, startAfter, daoProposalPageLimit, height)
+		if err != nil {
+			return nil, err
+		}
+		for _, proposal := range page {
+			if len(status) == 0 || proposal.Proposal.Status == status {
+				proposals = append(proposals, proposal)
+			}
+		}
+		if len(page) < daoProposalPageLimit {
+			return proposals, nil
+		}
+		startAfter = page[len(page)-1].Id
+	}
+}
+
+// DaoVote votes on a proposal of a cw-dao proposal module with the from account.
+func (c *Client) DaoVote(proposalModule string, proposalId uint64, vote string) (string, error) {
+	msg, err := json.Marshal(map[string]interface{}{
+		"vote": map[string]interface{}{
+			"proposal_id": proposalId,
+			"vote":        vote,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.SendContractExecuteMsg(proposalModule, msg, nil)
+}
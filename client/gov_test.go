@@ -0,0 +1,182 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	xGovTypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	xGovTypesV1beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// newTestProposal returns a proposal with a legacy text content and a contract execute msg.
+func newTestProposal(t *testing.T, id uint64) *xGovTypes.Proposal {
+	done := core.UseSdkConfigContext("neutron")
+	defer done()
+
+	authority := types.AccAddress([]byte("gov_________________")).String()
+	legacy, err := xGovTypes.NewLegacyContent(xGovTypesV1beta1.NewTextProposal("title", "description"), authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execute := &xWasmTypes.MsgExecuteContract{
+		Sender:   authority,
+		Contract: "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq",
+		Msg:      []byte(`{"era_update":{}}`),
+	}
+	proposal, err := xGovTypes.NewProposal([]types.Msg{legacy, execute}, id, time.Unix(1700000000, 0), time.Unix(1700086400, 0), "", "title", "summary", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &proposal
+}
+
+// newBrokenProposal returns a proposal with a msg of a type the client does not know.
+func newBrokenProposal(t *testing.T, id uint64) *xGovTypes.Proposal {
+	proposal := newTestProposal(t, id)
+	proposal.Messages = append(proposal.Messages, &codecTypes.Any{TypeUrl: "/unknown.v1.MsgUnknown"})
+	return proposal
+}
+
+func TestDecodeProposal(t *testing.T) {
+	registry := MakeEncodingConfig().InterfaceRegistry
+	decoded, err := decodeProposal(registry, newTestProposal(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Msgs) != 2 || decoded.Msgs[1].ExecuteAction != "era_update" {
+		t.Fatalf("unexpected msgs %+v", decoded.Msgs)
+	}
+	if len(decoded.LegacyContents) != 1 || decoded.LegacyContents[0].GetTitle() != "title" {
+		t.Fatalf("unexpected legacy contents %+v", decoded.LegacyContents)
+	}
+
+	broken := newBrokenProposal(t, 2)
+	if _, err := decodeProposal(registry, broken); err == nil {
+		t.Fatal("expected decode error")
+	}
+	decodedProposals := decodeProposals(registry, []*xGovTypes.Proposal{newTestProposal(t, 1), broken, nil})
+	if len(decodedProposals) != 2 || decodedProposals[0].Err != nil {
+		t.Fatalf("unexpected decoded proposals %+v", decodedProposals)
+	}
+	if decodedProposals[1].Err == nil || decodedProposals[1].Proposal.Id != 2 {
+		t.Fatalf("broken proposal not kept with its error %+v", decodedProposals[1])
+	}
+}
+
+func TestGetProposals(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	proposals := []*xGovTypes.Proposal{newTestProposal(t, 1), newBrokenProposal(t, 2), newTestProposal(t, 3)}
+	// every page commits a block, the pages must still be read at one height
+	heights := make(map[int64]bool)
+	chain.HandleQuery("/cosmos.gov.v1.Query/Proposals", func(data []byte, height int64) ([]byte, error) {
+		req := xGovTypes.QueryProposalsRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		heights[height] = true
+		chain.CommitBlock()
+		start, end, page := testPage(req.Pagination, len(proposals))
+		return (&xGovTypes.QueryProposalsResponse{Proposals: proposals[start:end], Pagination: page}).Marshal()
+	})
+
+	decoded, err := c.GetProposals(xGovTypes.StatusNil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 3 {
+		t.Fatalf("got %d proposals", len(decoded))
+	}
+	if len(heights) != 1 {
+		t.Fatalf("pages read at heights %v", heights)
+	}
+	for i, proposal := range decoded {
+		// the broken proposal is kept with its error
+		broken := proposal.Proposal.Id == 2
+		if proposal.Proposal.Id != uint64(i+1) || (proposal.Err != nil) != broken {
+			t.Fatalf("unexpected proposal %d: %+v", i, proposal)
+		}
+		if !broken && (len(proposal.Msgs) != 2 || len(proposal.LegacyContents) != 1) {
+			t.Fatalf("proposal %d not decoded: %+v", i, proposal)
+		}
+	}
+}
+
+func TestMsgKind(t *testing.T) {
+	cases := []struct {
+		msg  string
+		want []string
+	}{
+		{`{"wasm":{"migrate":{"contract_addr":"neutron1pool","new_code_id":2}}}`, []string{"wasm", "migrate"}},
+		{`{"custom":{"param_change":{}}}`, []string{"custom", "param_change"}},
+		{`{"bank":{"send":{},"burn":{}}}`, []string{"bank"}},
+		{`{"stargate":"raw"}`, []string{"stargate"}},
+	}
+	for _, c := range cases {
+		got, err := MsgKind(json.RawMessage(c.msg))
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("MsgKind(%s) = %v %v, want %v", c.msg, got, err, c.want)
+		}
+	}
+	for _, msg := range []string{`{}`, `[]`, `{"a":1,"b":2}`} {
+		if _, err := MsgKind(json.RawMessage(msg)); err == nil {
+			t.Errorf("MsgKind(%s) expected error", msg)
+		}
+	}
+}
+
+func TestGetDaoProposals(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	module := "neutron1436kxs0w2es6xlqpp9rd35e3d0cjnw4sv8j3a7483sgks29jqwgshlt6zh"
+
+	// a full first page makes the walk ask for the proposals after its last id
+	page := func(ids ...uint64) []byte {
+		proposals := make([]string, 0, len(ids))
+		for _, id := range ids {
+			status := DaoProposalStatusExecuted
+			if id%2 == 0 {
+				status = DaoProposalStatusOpen
+			}
+			proposals = append(proposals, fmt.Sprintf(`{"id":%d,"proposal":{"title":"p%d","status":"%s","msgs":[]}}`, id, id, status))
+		}
+		return []byte(`{"proposals":[` + strings.Join(proposals, ",") + `]}`)
+	}
+	first := make([]uint64, 0, daoProposalPageLimit)
+	for id := uint64(1); id <= daoProposalPageLimit; id++ {
+		first = append(first, id)
+	}
+	if err := chain.SetSmartQueryResponse(module, []byte(fmt.Sprintf(`{"list_proposals":{"limit":%d}}`, daoProposalPageLimit)), page(first...)); err != nil {
+		t.Fatal(err)
+	}
+	req := fmt.Sprintf(`{"list_proposals":{"start_after":%d,"limit":%d}}`, daoProposalPageLimit, daoProposalPageLimit)
+	if err := chain.SetSmartQueryResponse(module, []byte(req), page(daoProposalPageLimit+1, daoProposalPageLimit+2)); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := c.GetDaoProposals(module, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != daoProposalPageLimit+2 || all[len(all)-1].Id != daoProposalPageLimit+2 {
+		t.Fatalf("got %d proposals", len(all))
+	}
+	open, err := c.GetDaoProposals(module, DaoProposalStatusOpen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != daoProposalPageLimit/2+1 {
+		t.Fatalf("got %d open proposals", len(open))
+	}
+	for _, proposal := range open {
+		if proposal.Proposal.Status != DaoProposalStatusOpen {
+			t.Fatalf("unexpected proposal %+v", proposal)
+		}
+	}
+}
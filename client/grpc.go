@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// grpcFallbackInterval is how long module queries go through rpc abci_query after every
+// grpc endpoint failed in a row.
+const grpcFallbackInterval = time.Minute
+
+// WithGRPCEndpoints sends module queries to cosmos grpc endpoints instead of rpc abci_query.
+// An endpoint is host:port, or a url with scheme https for tls. Status, blocks, txs,
+// broadcast and height pinned queries keep using the rpc endpoints. When no grpc endpoint
+// answers, queries fall back to rpc for a while and grpc is tried again afterwards.
+func WithGRPCEndpoints(endpoints []string) Option {
+	return func(c *Client) error {
+		if len(endpoints) == 0 {
+			return fmt.Errorf("no grpc endpoint")
+		}
+		c.grpcEndpointList = endpoints
+		return nil
+	}
+}
+
+// grpcEndpoints is nil when grpc is disabled, every method is safe on nil.
+type grpcEndpoints struct {
+	logger log.Logger
+
+	mutex         sync.Mutex
+	urls          []string
+	conns         []*grpc.ClientConn
+	index         int
+	failures      int
+	fallbackUntil time.Time
+}
+
+func newGrpcEndpoints(urls []string, registry codecTypes.InterfaceRegistry, timeout time.Duration, metrics *clientMetrics, logger log.Logger) (*grpcEndpoints, error) {
+	g := &grpcEndpoints{logger: logger, urls: urls}
+	for _, endpoint := range urls {
+		target, creds, err := parseGrpcEndpoint(endpoint)
+		if err != nil {
+			g.close()
+			return nil, err
+		}
+		// dial doesn't block, an unreachable endpoint fails on its first query
+		conn, err := grpc.Dial(target,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(grpc.ForceCodec(codec.NewProtoCodec(registry).GRPCCodec())),
+			grpc.WithChainUnaryInterceptor(metricsInterceptor(endpoint, metrics), timeoutInterceptor(timeout)))
+		if err != nil {
+			g.close()
+			return nil, fmt.Errorf("dial grpc endpoint %s err: %w", endpoint, err)
+		}
+		g.conns = append(g.conns, conn)
+	}
+	return g, nil
+}
+
+func parseGrpcEndpoint(endpoint string) (string, credentials.TransportCredentials, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		// plain host:port
+		return endpoint, insecure.NewCredentials(), nil
+	}
+	switch u.Scheme {
+	case "https", "grpcs":
+		return u.Host, credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}), nil
+	case "http", "grpc", "tcp":
+		return u.Host, insecure.NewCredentials(), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported grpc endpoint scheme: %s", endpoint)
+	}
+}
+
+// current returns the conn module queries should use, nil while falling back to rpc.
+func (g *grpcEndpoints) current() *grpc.ClientConn {
+	if g == nil {
+		return nil
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if time.Now().Before(g.fallbackUntil) {
+		return nil
+	}
+	return g.conns[g.index]
+}
+
+// markFailed switches to the next grpc endpoint, or to rpc once all of them failed in a
+// row. It returns false when err is not a grpc connection err, which is left to the caller.
+func (g *grpcEndpoints) markFailed(err error) bool {
+	if g == nil || !isGrpcConnectionError(err) {
+		return false
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if time.Now().Before(g.fallbackUntil) {
+		return false
+	}
+
+	g.failures++
+	if g.failures >= len(g.conns) {
+		g.failures = 0
+		g.fallbackUntil = time.Now().Add(grpcFallbackInterval)
+		g.logger.Warn("grpc endpoints unavailable, fall back to rpc", "until", g.fallbackUntil, "err", err)
+		return true
+	}
+	g.index = (g.index + 1) % len(g.conns)
+	g.logger.Debug("change grpc endpoint", "endpoint", g.urls[g.index], "err", err)
+	return true
+}
+
+func (g *grpcEndpoints) markOk() {
+	if g == nil {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.failures = 0
+}
+
+func (g *grpcEndpoints) close() {
+	if g == nil {
+		return
+	}
+	for _, conn := range g.conns {
+		conn.Close()
+	}
+}
+
+// timeoutInterceptor bounds every call like WithRPCTimeout bounds rpc requests.
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// metricsInterceptor records grpc calls in the rpc request metrics, like instrumentedRPC
+// does for rpc endpoints.
+func metricsInterceptor(endpoint string, metrics *clientMetrics) grpc.UnaryClientInterceptor {
+	endpoint = endpointLabel(endpoint)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		metrics.observeRequest(endpoint, method, start, err)
+		return err
+	}
+}
+
+// isGrpcConnectionError reports errs of a grpc endpoint that can't serve, not of the query.
+func isGrpcConnectionError(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// abciCtx is the client ctx without grpc, height pinned queries must use it since grpc
+// ignores clientCtx.Height. It is read under the lock of ChangeEndpoint.
+func (c *Client) abciCtx() client.Context {
+	c.changeEndpointMutex.Lock()
+	defer c.changeEndpointMutex.Unlock()
+	return c.clientCtx
+}
+
+// CloseGRPC closes the grpc connections, queries keep working over rpc afterwards. It must
+// not be called concurrently with queries.
+func (c *Client) CloseGRPC() {
+	g := c.grpc
+	c.grpc = nil
+	g.close()
+}
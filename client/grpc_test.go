@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"google.golang.org/grpc"
+)
+
+type grpcWasmServer struct {
+	xWasmTypes.UnimplementedQueryServer
+}
+
+func (*grpcWasmServer) SmartContractState(context.Context, *xWasmTypes.QuerySmartContractStateRequest) (*xWasmTypes.QuerySmartContractStateResponse, error) {
+	return &xWasmTypes.QuerySmartContractStateResponse{Data: []byte(`{"via":"grpc"}`)}, nil
+}
+
+// grpcSlowWasmServer answers only once the caller gave up.
+type grpcSlowWasmServer struct {
+	xWasmTypes.UnimplementedQueryServer
+}
+
+func (*grpcSlowWasmServer) SmartContractState(ctx context.Context, _ *xWasmTypes.QuerySmartContractStateRequest) (*xWasmTypes.QuerySmartContractStateResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func startGrpcServer(t *testing.T) (string, *grpc.Server) {
+	return startGrpcWasmServer(t, &grpcWasmServer{})
+}
+
+func startGrpcWasmServer(t *testing.T, wasmServer xWasmTypes.QueryServer) (string, *grpc.Server) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ForceServerCodec(codec.NewProtoCodec(MakeEncodingConfig().InterfaceRegistry).GRPCCodec()))
+	xWasmTypes.RegisterQueryServer(server, wasmServer)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), server
+}
+
+func TestGrpcTransport(t *testing.T) {
+	chain := clienttest.NewChain("neutron-test-1")
+	defer chain.Close()
+	if err := chain.SetSmartQueryResponse(failoverContract, []byte(`{"era":{}}`), []byte(`{"via":"abci"}`)); err != nil {
+		t.Fatal(err)
+	}
+	grpcAddr, server := startGrpcServer(t)
+
+	// the first grpc endpoint refuses connections
+	c, err := NewClient(nil, "", "0.005untrn", "neutron", []string{chain.URL()}, log.NewLog("client", "test"),
+		WithGRPCEndpoints([]string{"127.0.0.1:1", "http://" + grpcAddr}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.CloseGRPC()
+
+	query := func() string {
+		res, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(res.Data)
+	}
+	if got := query(); got != `{"via":"grpc"}` {
+		t.Fatalf("got %s, want the grpc answer", got)
+	}
+
+	// height pinned queries stay on rpc
+	res, err := c.QuerySmartContractStateWithHeight(failoverContract, []byte(`{"era":{}}`), chain.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"via":"abci"}` {
+		t.Fatalf("pinned query got %s, want the abci answer", res.Data)
+	}
+
+	// no grpc endpoint left, queries fall back to rpc
+	server.Stop()
+	if got := query(); got != `{"via":"abci"}` {
+		t.Fatalf("got %s, want the abci answer", got)
+	}
+	if c.grpc.current() != nil {
+		t.Fatal("grpc still used after all endpoints failed")
+	}
+}
+
+func TestGrpcMetrics(t *testing.T) {
+	chain := clienttest.NewChain("neutron-test-1")
+	defer chain.Close()
+	grpcAddr, _ := startGrpcServer(t)
+	// credentials in the endpoint must not become a label
+	endpoint := "http://relayer:secret@" + grpcAddr
+	reg := prometheus.NewRegistry()
+	c, err := NewClient(nil, "", "0.005untrn", "neutron", []string{chain.URL()}, log.NewLog("client", "test"),
+		WithMetrics(reg), WithGRPCEndpoints([]string{endpoint}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.CloseGRPC()
+
+	if _, err := c.QuerySmartContractState(failoverContract, []byte(`{"era":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	method := "/cosmwasm.wasm.v1.Query/SmartContractState"
+	if got := testutil.ToFloat64(c.metrics.requests.WithLabelValues(grpcAddr, method, "ok")); got != 1 {
+		t.Fatalf("grpc requests %v", got)
+	}
+}
+
+func TestGrpcCallerDeadline(t *testing.T) {
+	chain := clienttest.NewChain("neutron-test-1")
+	defer chain.Close()
+	slowAddr, _ := startGrpcWasmServer(t, &grpcSlowWasmServer{})
+	c, err := NewClient(nil, "", "0.005untrn", "neutron", []string{chain.URL()}, log.NewLog("client", "test"),
+		WithGRPCEndpoints([]string{slowAddr}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.CloseGRPC()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = c.QuerySmartContractStateWithContext(ctx, failoverContract, []byte(`{"era":{}}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got err %v, want the caller deadline", err)
+	}
+	// the caller's deadline says nothing about the endpoint
+	if c.grpc.current() == nil || c.grpc.failures != 0 {
+		t.Fatal("grpc endpoint marked failed on the caller deadline")
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+)
+
+//go:generate mockgen -source=interfaces.go -destination=mocks/mocks.go -package=mocks
+
+// Querier reads accounts, balances and txs from the chain.
+type Querier interface {
+	GetChainId() (string, error)
+	GetCurrentBlockHeight() (int64, error)
+	QueryTxByHash(hashHexStr string) (*types.TxResponse, error)
+	QueryAccount(addr types.AccAddress) (client.Account, error)
+	GetAccount() (client.Account, error)
+	QueryBalance(addr types.AccAddress, denom string, height int64) (*xBankTypes.QueryBalanceResponse, error)
+	GetAllBalances(addr types.AccAddress, height int64) (types.Coins, error)
+}
+
+// TxSender signs txs with the from key and broadcasts them.
+type TxSender interface {
+	GetAccountPrefix() string
+	GetFromAddress() types.AccAddress
+	GetTxConfig() client.TxConfig
+	ConstructAndSignTxWithMemo(memo string, msgs ...types.Msg) ([]byte, error)
+	BroadcastTx(tx []byte) (string, error)
+	BroadcastTxResponse(ctx context.Context, tx []byte) (*types.TxResponse, error)
+	WaitTxIncluded(txHash string, timeout time.Duration) (*types.TxResponse, error)
+}
+
+// BlockSource serves blocks and the txs they contain.
+type BlockSource interface {
+	GetCurrentBlockHeight() (int64, error)
+	QueryBlock(height int64) (*ctypes.ResultBlock, error)
+	GetBlockTxsWithParseErrSkip(height int64) ([]*types.TxResponse, error)
+	GetBlockTxsByBlockResults(height int64) ([]*types.TxResponse, error)
+}
+
+// ContractClient queries and executes wasm contracts and reads their sudo failures.
+type ContractClient interface {
+	QuerySmartContractState(contract string, req []byte) (*xWasmTypes.QuerySmartContractStateResponse, error)
+	QuerySmartContractStateWithHeight(contract string, req []byte, height int64) (*xWasmTypes.QuerySmartContractStateResponse, error)
+	SendContractExecuteMsg(contract string, msg []byte, amount types.Coins) (string, error)
+	GetAddressFailures(contract string) ([]contractmanagerTypes.Failure, error)
+	DecodeFailure(contract string, failure contractmanagerTypes.Failure) FailureEvent
+}
+
+var (
+	_ Querier        = (*Client)(nil)
+	_ TxSender       = (*Client)(nil)
+	_ BlockSource    = (*Client)(nil)
+	_ ContractClient = (*Client)(nil)
+)
+
+// isNil reports a nil interface or one holding a nil pointer, e.g. a (*Client)(nil) passed
+// as a BlockSource, which a plain nil comparison misses.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "neutron_relay_client"
+
+// clientMetrics is nil when metrics are disabled, every method is a no-op on nil.
+type clientMetrics struct {
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	failedAttempts   *prometheus.CounterVec
+	endpointSwitches prometheus.Counter
+	broadcasts       *prometheus.CounterVec
+	gas              *prometheus.HistogramVec
+	sequence         prometheus.Gauge
+}
+
+// WithMetrics registers the client collectors on reg. Use a prometheus.WrapRegistererWith
+// registerer to tell several clients of one process apart.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(c *Client) error {
+		m := newClientMetrics()
+		for _, collector := range m.collectors() {
+			if err := reg.Register(collector); err != nil {
+				return err
+			}
+		}
+		c.metrics = m
+		return nil
+	}
+}
+
+func newClientMetrics() *clientMetrics {
+	return &clientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_requests_total",
+			Help:      "RPC requests by endpoint, method and status.",
+		}, []string{"endpoint", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_request_duration_seconds",
+			Help:      "RPC request latency by endpoint and method.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"endpoint", "method"}),
+		failedAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "failed_attempts_total",
+			Help:      "Failed attempts of retried client calls by error kind, including the last attempt.",
+		}, []string{"kind"}),
+		endpointSwitches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "endpoint_switches_total",
+			Help:      "Times the current endpoint was changed.",
+		}),
+		broadcasts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "broadcasts_total",
+			Help:      "Broadcast results by CheckTx code and codespace.",
+		}, []string{"code", "codespace"}),
+		gas: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_gas",
+			Help:      "Gas estimated for signed txs and gas used by included txs.",
+			Buckets:   prometheus.ExponentialBuckets(50000, 2, 10),
+		}, []string{"kind"}),
+		sequence: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "account_sequence",
+			Help:      "Sequence of the last tx signed by the from account.",
+		}),
+	}
+}
+
+func (m *clientMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.requestDuration, m.failedAttempts, m.endpointSwitches, m.broadcasts, m.gas, m.sequence}
+}
+
+// endpointLabel returns the host of an endpoint url, paths, queries and user info may carry
+// api keys and must not end up in metrics.
+func endpointLabel(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	// plain host:port
+	if i := strings.LastIndex(endpoint, "@"); i >= 0 {
+		endpoint = endpoint[i+1:]
+	}
+	if i := strings.IndexAny(endpoint, "/?"); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	return endpoint
+}
+
+func (m *clientMetrics) observeRequest(endpoint, method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.requests.WithLabelValues(endpoint, method, status).Inc()
+	m.requestDuration.WithLabelValues(endpoint, method).Observe(time.Since(start).Seconds())
+}
+
+func (m *clientMetrics) observeFailedAttempt(err error) {
+	if m == nil {
+		return
+	}
+	kind := "business"
+	if isConnectionError(err) {
+		kind = "connection"
+	}
+	m.failedAttempts.WithLabelValues(kind).Inc()
+}
+
+func (m *clientMetrics) observeEndpointSwitch() {
+	if m == nil {
+		return
+	}
+	m.endpointSwitches.Inc()
+}
+
+func (m *clientMetrics) observeBroadcast(code uint32, codespace string) {
+	if m == nil {
+		return
+	}
+	m.broadcasts.WithLabelValues(strconv.FormatUint(uint64(code), 10), codespace).Inc()
+}
+
+func (m *clientMetrics) observeGasEstimated(gas uint64, sequence uint64) {
+	if m == nil {
+		return
+	}
+	m.gas.WithLabelValues("estimated").Observe(float64(gas))
+	m.sequence.Set(float64(sequence))
+}
+
+func (m *clientMetrics) observeGasUsed(gasUsed int64) {
+	if m == nil {
+		return
+	}
+	m.gas.WithLabelValues("used").Observe(float64(gasUsed))
+}
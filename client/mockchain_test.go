@@ -0,0 +1,145 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	xAuthTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// newMockChainClient returns a client signing with a funded in-memory key on a fake chain.
+func newMockChainClient(t *testing.T) (*Client, *clienttest.Chain) {
+	chain := clienttest.NewChain("neutron-test-1")
+	t.Cleanup(chain.Close)
+
+	kr, account := newTestAccount(t)
+	chain.SetAccount(account)
+
+	c, err := NewClient(kr, "relayer", "0.005untrn", "neutron", []string{chain.URL()}, log.NewLog("client", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, chain
+}
+
+// newTestAccount creates the key under the neutron prefix, bech32 strings are cached by
+// address bytes so the key must not be rendered with another prefix first.
+func newTestAccount(t *testing.T) (keyring.Keyring, *xAuthTypes.BaseAccount) {
+	done := core.UseSdkConfigContext("neutron")
+	defer done()
+
+	kr := keyring.NewInMemory(MakeEncodingConfig().Marshaler)
+	info, err := kr.NewAccount("relayer", testMnemonic, "", types.FullFundraiserPath, hd.Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := info.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr, xAuthTypes.NewBaseAccount(addr, nil, 7, 3)
+}
+
+func TestMockChainQueryAndSend(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	if c.Ctx().ChainID != "neutron-test-1" {
+		t.Fatalf("unexpected chain id %s", c.Ctx().ChainID)
+	}
+
+	contract := "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq"
+	if err := chain.SetSmartQueryResponse(contract, []byte(`{"era": {}}`), []byte(`{"era":12}`)); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.QuerySmartContractState(contract, []byte(`{"era":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"era":12}` {
+		t.Fatalf("unexpected smart query response %s", res.Data)
+	}
+
+	txHash, err := c.SendContractExecuteMsg(contract, []byte(`{"era_update":{}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.BroadcastTxs()) != 1 {
+		t.Fatalf("broadcast %d txs", len(chain.BroadcastTxs()))
+	}
+	included, err := c.WaitTxIncluded(txHash, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included.Height != chain.Height() {
+		t.Fatalf("tx included at %d, chain height %d", included.Height, chain.Height())
+	}
+
+	decoded, err := c.GetBlockDecodedTxs(included.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].Msgs[0].ExecuteAction != "era_update" {
+		t.Fatalf("unexpected decoded txs %+v", decoded)
+	}
+}
+
+func TestMockChainRejectedBroadcast(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	chain.SetCheckTxResult(32, "sdk", "account sequence mismatch")
+
+	_, err := c.SendContractExecuteMsg("neutron1pool", []byte(`{"era_update":{}}`), nil)
+	if err == nil {
+		t.Fatal("expected broadcast error")
+	}
+	if len(chain.BroadcastTxs()) != 0 || chain.Height() != 1 {
+		t.Fatal("rejected tx was committed")
+	}
+}
+
+func TestMockChainDeliverTxCallback(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	var deliveredAt int64
+	chain.SetDeliverTxHandler(func([]byte) abci.ResponseDeliverTx {
+		// the handler may call back into the chain
+		deliveredAt = chain.Height() + 1
+		return abci.ResponseDeliverTx{}
+	})
+
+	txHash, err := c.SendContractExecuteMsg("neutron1pool", []byte(`{"era_update":{}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	included, err := c.WaitTxIncluded(txHash, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included.Height != deliveredAt {
+		t.Fatalf("tx included at %d, delivered at %d", included.Height, deliveredAt)
+	}
+}
+
+// testPage returns the bounds of the page req asks for out of total items with two items per
+// page, the page key is the index of the first item of the page.
+func testPage(req *query.PageRequest, total int) (int, int, *query.PageResponse) {
+	start := 0
+	if len(req.GetKey()) != 0 {
+		start = int(req.Key[0])
+	}
+	end := start + 2
+	res := &query.PageResponse{Total: uint64(total)}
+	if end < total {
+		res.NextKey = []byte{byte(end)}
+	} else {
+		end = total
+	}
+	return start, end, res
+}
@@ -0,0 +1,458 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	types "github.com/CosmWasm/wasmd/x/wasm/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	client "github.com/cosmos/cosmos-sdk/client"
+	types0 "github.com/cosmos/cosmos-sdk/types"
+	types1 "github.com/cosmos/cosmos-sdk/x/bank/types"
+	gomock "github.com/golang/mock/gomock"
+	types2 "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+	client0 "github.com/stafihub/neutron-relay-sdk/client"
+)
+
+// MockQuerier is a mock of Querier interface.
+type MockQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuerierMockRecorder
+}
+
+// MockQuerierMockRecorder is the mock recorder for MockQuerier.
+type MockQuerierMockRecorder struct {
+	mock *MockQuerier
+}
+
+// NewMockQuerier creates a new mock instance.
+func NewMockQuerier(ctrl *gomock.Controller) *MockQuerier {
+	mock := &MockQuerier{ctrl: ctrl}
+	mock.recorder = &MockQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuerier) EXPECT() *MockQuerierMockRecorder {
+	return m.recorder
+}
+
+// GetAccount mocks base method.
+func (m *MockQuerier) GetAccount() (client.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount")
+	ret0, _ := ret[0].(client.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockQuerierMockRecorder) GetAccount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockQuerier)(nil).GetAccount))
+}
+
+// GetAllBalances mocks base method.
+func (m *MockQuerier) GetAllBalances(addr types0.AccAddress, height int64) (types0.Coins, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllBalances", addr, height)
+	ret0, _ := ret[0].(types0.Coins)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllBalances indicates an expected call of GetAllBalances.
+func (mr *MockQuerierMockRecorder) GetAllBalances(addr, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllBalances", reflect.TypeOf((*MockQuerier)(nil).GetAllBalances), addr, height)
+}
+
+// GetChainId mocks base method.
+func (m *MockQuerier) GetChainId() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChainId")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChainId indicates an expected call of GetChainId.
+func (mr *MockQuerierMockRecorder) GetChainId() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChainId", reflect.TypeOf((*MockQuerier)(nil).GetChainId))
+}
+
+// GetCurrentBlockHeight mocks base method.
+func (m *MockQuerier) GetCurrentBlockHeight() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentBlockHeight")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentBlockHeight indicates an expected call of GetCurrentBlockHeight.
+func (mr *MockQuerierMockRecorder) GetCurrentBlockHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentBlockHeight", reflect.TypeOf((*MockQuerier)(nil).GetCurrentBlockHeight))
+}
+
+// QueryAccount mocks base method.
+func (m *MockQuerier) QueryAccount(addr types0.AccAddress) (client.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryAccount", addr)
+	ret0, _ := ret[0].(client.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryAccount indicates an expected call of QueryAccount.
+func (mr *MockQuerierMockRecorder) QueryAccount(addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryAccount", reflect.TypeOf((*MockQuerier)(nil).QueryAccount), addr)
+}
+
+// QueryBalance mocks base method.
+func (m *MockQuerier) QueryBalance(addr types0.AccAddress, denom string, height int64) (*types1.QueryBalanceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBalance", addr, denom, height)
+	ret0, _ := ret[0].(*types1.QueryBalanceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBalance indicates an expected call of QueryBalance.
+func (mr *MockQuerierMockRecorder) QueryBalance(addr, denom, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBalance", reflect.TypeOf((*MockQuerier)(nil).QueryBalance), addr, denom, height)
+}
+
+// QueryTxByHash mocks base method.
+func (m *MockQuerier) QueryTxByHash(hashHexStr string) (*types0.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryTxByHash", hashHexStr)
+	ret0, _ := ret[0].(*types0.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryTxByHash indicates an expected call of QueryTxByHash.
+func (mr *MockQuerierMockRecorder) QueryTxByHash(hashHexStr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryTxByHash", reflect.TypeOf((*MockQuerier)(nil).QueryTxByHash), hashHexStr)
+}
+
+// MockTxSender is a mock of TxSender interface.
+type MockTxSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxSenderMockRecorder
+}
+
+// MockTxSenderMockRecorder is the mock recorder for MockTxSender.
+type MockTxSenderMockRecorder struct {
+	mock *MockTxSender
+}
+
+// NewMockTxSender creates a new mock instance.
+func NewMockTxSender(ctrl *gomock.Controller) *MockTxSender {
+	mock := &MockTxSender{ctrl: ctrl}
+	mock.recorder = &MockTxSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxSender) EXPECT() *MockTxSenderMockRecorder {
+	return m.recorder
+}
+
+// BroadcastTx mocks base method.
+func (m *MockTxSender) BroadcastTx(tx []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BroadcastTx", tx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BroadcastTx indicates an expected call of BroadcastTx.
+func (mr *MockTxSenderMockRecorder) BroadcastTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastTx", reflect.TypeOf((*MockTxSender)(nil).BroadcastTx), tx)
+}
+
+// BroadcastTxResponse mocks base method.
+func (m *MockTxSender) BroadcastTxResponse(ctx context.Context, tx []byte) (*types0.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BroadcastTxResponse", ctx, tx)
+	ret0, _ := ret[0].(*types0.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BroadcastTxResponse indicates an expected call of BroadcastTxResponse.
+func (mr *MockTxSenderMockRecorder) BroadcastTxResponse(ctx, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastTxResponse", reflect.TypeOf((*MockTxSender)(nil).BroadcastTxResponse), ctx, tx)
+}
+
+// ConstructAndSignTxWithMemo mocks base method.
+func (m *MockTxSender) ConstructAndSignTxWithMemo(memo string, msgs ...types0.Msg) ([]byte, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{memo}
+	for _, a := range msgs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ConstructAndSignTxWithMemo", varargs...)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConstructAndSignTxWithMemo indicates an expected call of ConstructAndSignTxWithMemo.
+func (mr *MockTxSenderMockRecorder) ConstructAndSignTxWithMemo(memo interface{}, msgs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{memo}, msgs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConstructAndSignTxWithMemo", reflect.TypeOf((*MockTxSender)(nil).ConstructAndSignTxWithMemo), varargs...)
+}
+
+// GetAccountPrefix mocks base method.
+func (m *MockTxSender) GetAccountPrefix() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountPrefix")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetAccountPrefix indicates an expected call of GetAccountPrefix.
+func (mr *MockTxSenderMockRecorder) GetAccountPrefix() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountPrefix", reflect.TypeOf((*MockTxSender)(nil).GetAccountPrefix))
+}
+
+// GetFromAddress mocks base method.
+func (m *MockTxSender) GetFromAddress() types0.AccAddress {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFromAddress")
+	ret0, _ := ret[0].(types0.AccAddress)
+	return ret0
+}
+
+// GetFromAddress indicates an expected call of GetFromAddress.
+func (mr *MockTxSenderMockRecorder) GetFromAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFromAddress", reflect.TypeOf((*MockTxSender)(nil).GetFromAddress))
+}
+
+// GetTxConfig mocks base method.
+func (m *MockTxSender) GetTxConfig() client.TxConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTxConfig")
+	ret0, _ := ret[0].(client.TxConfig)
+	return ret0
+}
+
+// GetTxConfig indicates an expected call of GetTxConfig.
+func (mr *MockTxSenderMockRecorder) GetTxConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxConfig", reflect.TypeOf((*MockTxSender)(nil).GetTxConfig))
+}
+
+// WaitTxIncluded mocks base method.
+func (m *MockTxSender) WaitTxIncluded(txHash string, timeout time.Duration) (*types0.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitTxIncluded", txHash, timeout)
+	ret0, _ := ret[0].(*types0.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitTxIncluded indicates an expected call of WaitTxIncluded.
+func (mr *MockTxSenderMockRecorder) WaitTxIncluded(txHash, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitTxIncluded", reflect.TypeOf((*MockTxSender)(nil).WaitTxIncluded), txHash, timeout)
+}
+
+// MockBlockSource is a mock of BlockSource interface.
+type MockBlockSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockSourceMockRecorder
+}
+
+// MockBlockSourceMockRecorder is the mock recorder for MockBlockSource.
+type MockBlockSourceMockRecorder struct {
+	mock *MockBlockSource
+}
+
+// NewMockBlockSource creates a new mock instance.
+func NewMockBlockSource(ctrl *gomock.Controller) *MockBlockSource {
+	mock := &MockBlockSource{ctrl: ctrl}
+	mock.recorder = &MockBlockSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockSource) EXPECT() *MockBlockSourceMockRecorder {
+	return m.recorder
+}
+
+// GetBlockTxsByBlockResults mocks base method.
+func (m *MockBlockSource) GetBlockTxsByBlockResults(height int64) ([]*types0.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockTxsByBlockResults", height)
+	ret0, _ := ret[0].([]*types0.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockTxsByBlockResults indicates an expected call of GetBlockTxsByBlockResults.
+func (mr *MockBlockSourceMockRecorder) GetBlockTxsByBlockResults(height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockTxsByBlockResults", reflect.TypeOf((*MockBlockSource)(nil).GetBlockTxsByBlockResults), height)
+}
+
+// GetBlockTxsWithParseErrSkip mocks base method.
+func (m *MockBlockSource) GetBlockTxsWithParseErrSkip(height int64) ([]*types0.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockTxsWithParseErrSkip", height)
+	ret0, _ := ret[0].([]*types0.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockTxsWithParseErrSkip indicates an expected call of GetBlockTxsWithParseErrSkip.
+func (mr *MockBlockSourceMockRecorder) GetBlockTxsWithParseErrSkip(height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockTxsWithParseErrSkip", reflect.TypeOf((*MockBlockSource)(nil).GetBlockTxsWithParseErrSkip), height)
+}
+
+// GetCurrentBlockHeight mocks base method.
+func (m *MockBlockSource) GetCurrentBlockHeight() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentBlockHeight")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentBlockHeight indicates an expected call of GetCurrentBlockHeight.
+func (mr *MockBlockSourceMockRecorder) GetCurrentBlockHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentBlockHeight", reflect.TypeOf((*MockBlockSource)(nil).GetCurrentBlockHeight))
+}
+
+// QueryBlock mocks base method.
+func (m *MockBlockSource) QueryBlock(height int64) (*coretypes.ResultBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBlock", height)
+	ret0, _ := ret[0].(*coretypes.ResultBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBlock indicates an expected call of QueryBlock.
+func (mr *MockBlockSourceMockRecorder) QueryBlock(height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBlock", reflect.TypeOf((*MockBlockSource)(nil).QueryBlock), height)
+}
+
+// MockContractClient is a mock of ContractClient interface.
+type MockContractClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockContractClientMockRecorder
+}
+
+// MockContractClientMockRecorder is the mock recorder for MockContractClient.
+type MockContractClientMockRecorder struct {
+	mock *MockContractClient
+}
+
+// NewMockContractClient creates a new mock instance.
+func NewMockContractClient(ctrl *gomock.Controller) *MockContractClient {
+	mock := &MockContractClient{ctrl: ctrl}
+	mock.recorder = &MockContractClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContractClient) EXPECT() *MockContractClientMockRecorder {
+	return m.recorder
+}
+
+// DecodeFailure mocks base method.
+func (m *MockContractClient) DecodeFailure(contract string, failure types2.Failure) client0.FailureEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeFailure", contract, failure)
+	ret0, _ := ret[0].(client0.FailureEvent)
+	return ret0
+}
+
+// DecodeFailure indicates an expected call of DecodeFailure.
+func (mr *MockContractClientMockRecorder) DecodeFailure(contract, failure interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeFailure", reflect.TypeOf((*MockContractClient)(nil).DecodeFailure), contract, failure)
+}
+
+// GetAddressFailures mocks base method.
+func (m *MockContractClient) GetAddressFailures(contract string) ([]types2.Failure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddressFailures", contract)
+	ret0, _ := ret[0].([]types2.Failure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddressFailures indicates an expected call of GetAddressFailures.
+func (mr *MockContractClientMockRecorder) GetAddressFailures(contract interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddressFailures", reflect.TypeOf((*MockContractClient)(nil).GetAddressFailures), contract)
+}
+
+// QuerySmartContractState mocks base method.
+func (m *MockContractClient) QuerySmartContractState(contract string, req []byte) (*types.QuerySmartContractStateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuerySmartContractState", contract, req)
+	ret0, _ := ret[0].(*types.QuerySmartContractStateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuerySmartContractState indicates an expected call of QuerySmartContractState.
+func (mr *MockContractClientMockRecorder) QuerySmartContractState(contract, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySmartContractState", reflect.TypeOf((*MockContractClient)(nil).QuerySmartContractState), contract, req)
+}
+
+// QuerySmartContractStateWithHeight mocks base method.
+func (m *MockContractClient) QuerySmartContractStateWithHeight(contract string, req []byte, height int64) (*types.QuerySmartContractStateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuerySmartContractStateWithHeight", contract, req, height)
+	ret0, _ := ret[0].(*types.QuerySmartContractStateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuerySmartContractStateWithHeight indicates an expected call of QuerySmartContractStateWithHeight.
+func (mr *MockContractClientMockRecorder) QuerySmartContractStateWithHeight(contract, req, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySmartContractStateWithHeight", reflect.TypeOf((*MockContractClient)(nil).QuerySmartContractStateWithHeight), contract, req, height)
+}
+
+// SendContractExecuteMsg mocks base method.
+func (m *MockContractClient) SendContractExecuteMsg(contract string, msg []byte, amount types0.Coins) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendContractExecuteMsg", contract, msg, amount)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendContractExecuteMsg indicates an expected call of SendContractExecuteMsg.
+func (mr *MockContractClientMockRecorder) SendContractExecuteMsg(contract, msg, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendContractExecuteMsg", reflect.TypeOf((*MockContractClient)(nil).SendContractExecuteMsg), contract, msg, amount)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	contractmanagerTypes "github.com/neutron-org/neutron/v2/x/contractmanager/types"
+	"github.com/stafihub/neutron-relay-sdk/client"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+)
+
+func TestFailureWatcherWithMockClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	contract := "neutron1pool"
+	failure := contractmanagerTypes.Failure{Address: contract, Id: 3}
+
+	c := NewMockContractClient(ctrl)
+	c.EXPECT().GetAddressFailures(contract).Return([]contractmanagerTypes.Failure{failure}, nil).Times(2)
+	c.EXPECT().DecodeFailure(contract, failure).Return(client.FailureEvent{Contract: contract, Failure: failure}).Times(1)
+
+	events := make([]client.FailureEvent, 0)
+	store := client.NewFileFailureStore(filepath.Join(t.TempDir(), "failures.json"))
+	w, err := client.NewFailureWatcher(c, store, []string{contract}, func(event client.FailureEvent) {
+		events = append(events, event)
+	}, time.Minute, log.NewLog("client", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := w.CheckOnce(); err != nil || n != 1 {
+		t.Fatalf("first check: %d new, err %v", n, err)
+	}
+	// the failure was seen, it must not be decoded and reported again
+	if n, err := w.CheckOnce(); err != nil || n != 0 {
+		t.Fatalf("second check: %d new, err %v", n, err)
+	}
+	if len(events) != 1 || events[0].Failure.Id != 3 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option configures optional features of a Client in NewClient.
+type Option func(c *Client) error
+
+// WithRPCTimeout bounds every rpc request, by default requests wait on the endpoint forever.
+func WithRPCTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		c.rpcTimeout = timeout
+		return nil
+	}
+}
+
+// WithTxWaitTimeout bounds how long the staking and distribution txs wait for inclusion,
+// default one minute.
+func WithTxWaitTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("tx wait timeout must be positive")
+		}
+		c.txWaitTimeout = timeout
+		return nil
+	}
+}
@@ -2,7 +2,11 @@ package client
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"strings"
@@ -12,10 +16,12 @@ import (
 	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/stafihub/rtoken-relay-core/common/core"
 
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/types"
 	xAuthTx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	xAuthTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	xStakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	feerefunderTypes "github.com/neutron-org/neutron/v2/x/feerefunder/types"
@@ -40,12 +46,49 @@ func (c *Client) QueryTxByHash(hashHexStr string) (*types.TxResponse, error) {
 	return cc.(*types.TxResponse), nil
 }
 
+// queryTxOnce looks txHash up with a single request to the endpoint at index.
+func (c *Client) queryTxOnce(ctx context.Context, index int, txHash string) (*types.TxResponse, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, err
+	}
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	node := c.rpcClientList[index]
+	resTx, err := node.Tx(ctx, hash, true)
+	if err != nil {
+		return nil, err
+	}
+	block, err := node.Block(ctx, &resTx.Height)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.abciCtx().TxConfig.TxDecoder()(resTx.Tx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := tx.(intoAny)
+	if !ok {
+		return nil, fmt.Errorf("expecting a type implementing intoAny, got: %T", tx)
+	}
+	return types.NewResponseResultTx(resTx, p.AsAny(), block.Block.Time.Format(time.RFC3339)), nil
+}
+
 func (c *Client) QuerySmartContractState(contract string, req []byte) (*xWasmTypes.QuerySmartContractStateResponse, error) {
+	return c.QuerySmartContractStateWithContext(context.Background(), contract, req)
+}
+
+func (c *Client) QuerySmartContractStateWithContext(ctx context.Context, contract string, req []byte) (ret *xWasmTypes.QuerySmartContractStateResponse, err error) {
+	ctx, span := c.startSpan(ctx, "QuerySmartContractState", AttrContract.String(contract))
+	defer func() { endSpan(span, err) }()
+
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
 
-	cc, err := c.retry(func() (interface{}, error) {
-		return c.queryClient.SmartContractState(context.Background(), &xWasmTypes.QuerySmartContractStateRequest{
+	cc, err := c.retryWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		queryClient := xWasmTypes.NewQueryClient(c.Ctx())
+		return queryClient.SmartContractState(ctx, &xWasmTypes.QuerySmartContractStateRequest{
 			Address:   contract,
 			QueryData: req,
 		})
@@ -61,10 +104,8 @@ func (c *Client) QuerySmartContractStateWithHeight(contract string, req []byte,
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
 
-	client := c.Ctx().WithHeight(height)
-	queryClient := xWasmTypes.NewQueryClient(client)
-
-	cc, err := c.retry(func() (interface{}, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xWasmTypes.NewQueryClient(clientCtx)
 		return queryClient.SmartContractState(context.Background(), &xWasmTypes.QuerySmartContractStateRequest{
 			Address:   contract,
 			QueryData: req,
@@ -96,17 +137,7 @@ func (c *Client) QueryBlock(height int64) (*ctypes.ResultBlock, error) {
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
 
-	cc, err := c.retry(func() (interface{}, error) {
-		node, err := c.Ctx().GetNode()
-		if err != nil {
-			return nil, err
-		}
-		return node.Block(context.Background(), &height)
-	})
-	if err != nil {
-		return nil, err
-	}
-	return cc.(*ctypes.ResultBlock), nil
+	return c.queryBlock(height)
 }
 
 func (c *Client) QueryAccount(addr types.AccAddress) (client.Account, error) {
@@ -131,9 +162,8 @@ func (c *Client) QueryBalance(addr types.AccAddress, denom string, height int64)
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
 
-	cc, err := c.retry(func() (interface{}, error) {
-		client := c.Ctx().WithHeight(height)
-		queryClient := xBankTypes.NewQueryClient(client)
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
 		params := xBankTypes.NewQueryBalanceRequest(addr, denom)
 		return queryClient.Balance(context.Background(), params)
 	})
@@ -172,9 +202,20 @@ func (c *Client) GetAccount() (client.Account, error) {
 }
 
 func (c *Client) getAccount(height int64, addr types.AccAddress) (client.Account, error) {
-	cc, err := c.retry(func() (interface{}, error) {
-		client := c.Ctx().WithHeight(height)
-		return client.AccountRetriever.GetAccount(c.Ctx(), addr)
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		return clientCtx.AccountRetriever.GetAccount(clientCtx, addr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(client.Account), nil
+}
+
+// getAccountWithContext is getAccount at the latest height bounded by ctx, the sdk account
+// retriever and its rpc queries use context.Background.
+func (c *Client) getAccountWithContext(ctx context.Context, addr types.AccAddress) (client.Account, error) {
+	cc, err := c.retryWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return queryAccount(ctx, c.Ctx(), addr)
 	})
 	if err != nil {
 		return nil, err
@@ -182,6 +223,75 @@ func (c *Client) getAccount(height int64, addr types.AccAddress) (client.Account
 	return cc.(client.Account), nil
 }
 
+// queryAccount asks the grpc endpoint if clientCtx has one, otherwise it sends the abci
+// query itself so that ctx reaches the rpc request.
+func queryAccount(ctx context.Context, clientCtx client.Context, addr types.AccAddress) (xAuthTypes.AccountI, error) {
+	req := &xAuthTypes.QueryAccountRequest{Address: addr.String()}
+	res := &xAuthTypes.QueryAccountResponse{}
+	if clientCtx.GRPCClient != nil {
+		var err error
+		if res, err = xAuthTypes.NewQueryClient(clientCtx).Account(ctx, req); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := req.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		abciRes, err := clientCtx.Client.ABCIQueryWithOptions(ctx, "/cosmos.auth.v1beta1.Query/Account", data,
+			rpcClient.ABCIQueryOptions{Height: clientCtx.Height})
+		if err != nil {
+			return nil, err
+		}
+		if !abciRes.Response.IsOK() {
+			return nil, fmt.Errorf("query err with code: %d, codespace: %s, log: %s",
+				abciRes.Response.Code, abciRes.Response.Codespace, abciRes.Response.Log)
+		}
+		if err := res.Unmarshal(abciRes.Response.Value); err != nil {
+			return nil, err
+		}
+	}
+	var account xAuthTypes.AccountI
+	if err := clientCtx.InterfaceRegistry.UnpackAny(res.Account, &account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// QueryTxAfterSequence reads the sequence of the from account and then looks txHash up on
+// the same endpoint, so a tx included before the sequence advanced is found even if other
+// endpoints lag in indexing it. The response is nil if the endpoint doesn't know txHash.
+func (c *Client) QueryTxAfterSequence(txHash string) (uint64, *types.TxResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	type sequenceTx struct {
+		sequence uint64
+		res      *types.TxResponse
+	}
+	cc, err := c.retry(func() (interface{}, error) {
+		// the tx route is rpc only, read the account over the same rpc endpoint
+		clientCtx := c.abciCtx()
+		account, err := clientCtx.AccountRetriever.GetAccount(clientCtx, clientCtx.FromAddress)
+		if err != nil {
+			return nil, err
+		}
+		res, err := xAuthTx.QueryTx(clientCtx, txHash)
+		if err != nil {
+			if !isTxNotFoundError(err, txHash) {
+				return nil, err
+			}
+			res = nil
+		}
+		return &sequenceTx{sequence: account.GetSequence(), res: res}, nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	ret := cc.(*sequenceTx)
+	return ret.sequence, ret.res, nil
+}
+
 func (c *Client) GetTxs(events []string, page, limit int, orderBy string) (*types.SearchTxsResult, error) {
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
@@ -311,26 +421,63 @@ func (c *Client) Retry(f func() (interface{}, error)) (interface{}, error) {
 
 // only retry func when return connection err here
 func (c *Client) retry(f func() (interface{}, error)) (interface{}, error) {
+	return c.retryWithContext(context.Background(), func(context.Context) (interface{}, error) {
+		return f()
+	})
+}
+
+// retryWithContext is retry with a child span of ctx for every attempt.
+func (c *Client) retryWithContext(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	attempt := 0
+	try := func() (interface{}, error) {
+		// the caller gave up, don't blame the endpoint for it
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		attempt++
+		attemptCtx, span := c.startSpan(ctx, "retry attempt",
+			AttrAttempt.Int(attempt),
+			AttrEndpoint.String(c.endpointUrl(c.CurrentEndpointIndex())))
+		result, err := f(attemptCtx)
+		endSpan(span, err)
+		return result, err
+	}
+
 	var err error
 	var result interface{}
 	for i := 0; i < retryLimit; i++ {
-		result, err = f()
+		result, err = try()
 		if err != nil {
+			if isContextError(ctx, err) {
+				return nil, contextError(ctx, err)
+			}
+			c.metrics.observeFailedAttempt(err)
 			c.logger.Debug("retry:",
 				"endpoint index", c.CurrentEndpointIndex(),
 				"err", err)
+			// grpc endpoint down, try the next one or rpc at once
+			if c.grpc.markFailed(err) {
+				continue
+			}
 			// connection err case
 			if isConnectionError(err) {
 				c.ChangeEndpoint()
-				time.Sleep(waitTime)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(waitTime):
+				}
 				continue
 			}
 			// business err case or other err case not captured
 			for j := 0; j < len(c.rpcClientList)*2; j++ {
 				c.ChangeEndpoint()
-				subResult, subErr := f()
+				subResult, subErr := try()
 
 				if subErr != nil {
+					if isContextError(ctx, subErr) {
+						return nil, contextError(ctx, subErr)
+					}
 					c.logger.Debug("retry:",
 						"endpoint index", c.CurrentEndpointIndex(),
 						"subErr", err)
@@ -355,6 +502,7 @@ func (c *Client) retry(f func() (interface{}, error)) (interface{}, error) {
 
 		}
 		// no err, just return
+		c.grpc.markOk()
 		return result, err
 	}
 	return nil, fmt.Errorf("reach retry limit. err: %s", err)
@@ -377,11 +525,23 @@ func isConnectionError(err error) bool {
 		return isConnectionError(t.Err)
 
 	case syscall.Errno:
-		if t == syscall.ECONNREFUSED {
+		if t == syscall.ECONNREFUSED || t == syscall.ECONNRESET {
 			return true
 		}
 	}
 
+	// connection closed or body cut before the whole response arrived
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	// a node never answers a business err with broken json, the response is from a proxy
+	// or truncated
+	switch err.(type) {
+	case *json.SyntaxError:
+		return true
+	}
+
 	switch t := err.(type) {
 	case wrapError:
 		newErr := t.Unwrap()
@@ -408,6 +568,19 @@ func isConnectionError(err error) bool {
 	return false
 }
 
+// isContextError reports errs caused by the caller's ctx rather than by the endpoint.
+func isContextError(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled)
+}
+
+// contextError prefers ctx.Err(), transports report the caller's deadline in their own errs.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
 type wrapError interface {
 	Unwrap() error
 }
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+const (
+	defaultPageLimit      = 100
+	defaultBalanceWorkers = 8
+)
+
+// AddressBalances is the result of one address in QueryBalancesOfAddresses.
+type AddressBalances struct {
+	Address  string
+	Balances types.Coins
+	Err      error
+}
+
+func (c *Client) QueryAllBalances(addr types.AccAddress, pageReq *query.PageRequest, height int64) (*xBankTypes.QueryAllBalancesResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	address := addr.String()
+	done()
+
+	return c.queryAllBalances(address, pageReq, height)
+}
+
+func (c *Client) queryAllBalances(address string, pageReq *query.PageRequest, height int64) (*xBankTypes.QueryAllBalancesResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
+		return queryClient.AllBalances(context.Background(), &xBankTypes.QueryAllBalancesRequest{
+			Address:    address,
+			Pagination: pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QueryAllBalancesResponse), nil
+}
+
+// GetAllBalances walks all pages of QueryAllBalances.
+func (c *Client) GetAllBalances(addr types.AccAddress, height int64) (types.Coins, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	address := addr.String()
+	done()
+
+	return c.getAllBalances(address, height)
+}
+
+func (c *Client) getAllBalances(address string, height int64) (types.Coins, error) {
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	balances := types.NewCoins()
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryAllBalances(address, pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		balances = balances.Add(res.Balances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (c *Client) QuerySpendableBalances(addr types.AccAddress, pageReq *query.PageRequest, height int64) (*xBankTypes.QuerySpendableBalancesResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	address := addr.String()
+	done()
+
+	return c.querySpendableBalances(address, pageReq, height)
+}
+
+func (c *Client) querySpendableBalances(address string, pageReq *query.PageRequest, height int64) (*xBankTypes.QuerySpendableBalancesResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
+		return queryClient.SpendableBalances(context.Background(), &xBankTypes.QuerySpendableBalancesRequest{
+			Address:    address,
+			Pagination: pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QuerySpendableBalancesResponse), nil
+}
+
+// GetSpendableBalances walks all pages of QuerySpendableBalances.
+func (c *Client) GetSpendableBalances(addr types.AccAddress, height int64) (types.Coins, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	address := addr.String()
+	done()
+
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	balances := types.NewCoins()
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.querySpendableBalances(address, pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		balances = balances.Add(res.Balances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (c *Client) QueryTotalSupply(pageReq *query.PageRequest, height int64) (*xBankTypes.QueryTotalSupplyResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryTotalSupply(pageReq, height)
+}
+
+func (c *Client) queryTotalSupply(pageReq *query.PageRequest, height int64) (*xBankTypes.QueryTotalSupplyResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
+		return queryClient.TotalSupply(context.Background(), &xBankTypes.QueryTotalSupplyRequest{
+			Pagination: pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QueryTotalSupplyResponse), nil
+}
+
+// GetTotalSupply walks all pages of QueryTotalSupply.
+func (c *Client) GetTotalSupply(height int64) (types.Coins, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	supply := types.NewCoins()
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryTotalSupply(pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		supply = supply.Add(res.Supply...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
+
+func (c *Client) QuerySupplyOf(denom string, height int64) (*xBankTypes.QuerySupplyOfResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
+		return queryClient.SupplyOf(context.Background(), &xBankTypes.QuerySupplyOfRequest{Denom: denom})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QuerySupplyOfResponse), nil
+}
+
+func (c *Client) QueryDenomMetadata(denom string) (*xBankTypes.QueryDenomMetadataResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(c.Ctx())
+		return queryClient.DenomMetadata(context.Background(), &xBankTypes.QueryDenomMetadataRequest{Denom: denom})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QueryDenomMetadataResponse), nil
+}
+
+func (c *Client) QueryDenomsMetadata(pageReq *query.PageRequest) (*xBankTypes.QueryDenomsMetadataResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(c.Ctx())
+		return queryClient.DenomsMetadata(context.Background(), &xBankTypes.QueryDenomsMetadataRequest{Pagination: pageReq})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xBankTypes.QueryDenomsMetadataResponse), nil
+}
+
+// QueryBalancesOfAddresses fetches balances of addrs concurrently with at most workers
+// in-flight queries, all balances are returned when denom is empty. Results keep the order
+// of addrs and a failed address doesn't fail the others. A zero height is pinned to the
+// latest height once, so all addresses are read from the same state.
+func (c *Client) QueryBalancesOfAddresses(addrs []types.AccAddress, denom string, height int64, workers int) []AddressBalances {
+	if workers <= 0 {
+		workers = defaultBalanceWorkers
+	}
+
+	// convert once under the sdk config lock, the queries themselves only need strings
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.String()
+	}
+	done()
+
+	results := make([]AddressBalances, len(addresses))
+	height, err := c.pinHeight(height)
+	if err != nil {
+		for i, address := range addresses {
+			results[i] = AddressBalances{Address: address, Err: err}
+		}
+		return results
+	}
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = c.queryAddressBalances(addresses[i], denom, height)
+			}
+		}()
+	}
+	for i := range addresses {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func (c *Client) queryAddressBalances(address, denom string, height int64) AddressBalances {
+	ret := AddressBalances{Address: address}
+	if len(denom) == 0 {
+		ret.Balances, ret.Err = c.getAllBalances(address, height)
+		return ret
+	}
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xBankTypes.NewQueryClient(clientCtx)
+		return queryClient.Balance(context.Background(), &xBankTypes.QueryBalanceRequest{Address: address, Denom: denom})
+	})
+	if err != nil {
+		ret.Err = err
+		return ret
+	}
+	balance := cc.(*xBankTypes.QueryBalanceResponse).Balance
+	if balance != nil {
+		ret.Balances = types.NewCoins(*balance)
+	} else {
+		ret.Balances = types.NewCoins()
+	}
+	return ret
+}
+
+// pinHeight returns the latest height for a zero height, so that the pages of one walk are
+// read from the same state.
+func (c *Client) pinHeight(height int64) (int64, error) {
+	if height > 0 {
+		return height, nil
+	}
+	status, err := c.getStatus()
+	if err != nil {
+		return 0, err
+	}
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+// walkPages calls f with the next key until the page response has no next key.
+func walkPages(f func(pageReq *query.PageRequest) (*query.PageResponse, error)) error {
+	pageReq := &query.PageRequest{Limit: defaultPageLimit}
+	for {
+		pageRes, err := f(pageReq)
+		if err != nil {
+			return err
+		}
+		if pageRes == nil || len(pageRes.NextKey) == 0 {
+			return nil
+		}
+		pageReq = &query.PageRequest{Key: pageRes.NextKey, Limit: defaultPageLimit}
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func TestQueryBalancesOfAddresses(t *testing.T) {
+	c, chain := newMockChainClient(t)
+
+	done := core.UseSdkConfigContext("neutron")
+	addrs := []types.AccAddress{
+		types.AccAddress([]byte("address_a___________")),
+		types.AccAddress([]byte("address_b___________")),
+		types.AccAddress([]byte("address_c___________")),
+	}
+	amounts := make(map[string]int64)
+	for i, addr := range addrs {
+		amounts[addr.String()] = int64(i + 1)
+	}
+	done()
+
+	// blocks committed while the batch runs must not move its height
+	mutex := sync.Mutex{}
+	heights := make(map[int64]bool)
+	chain.HandleQuery(clienttest.BalanceQueryPath, func(data []byte, height int64) ([]byte, error) {
+		req := xBankTypes.QueryBalanceRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		mutex.Lock()
+		heights[height] = true
+		mutex.Unlock()
+		chain.CommitBlock()
+		coin := types.NewInt64Coin(req.Denom, amounts[req.Address])
+		return (&xBankTypes.QueryBalanceResponse{Balance: &coin}).Marshal()
+	})
+
+	// failover of other calls runs concurrently with the workers
+	stop := make(chan struct{})
+	switched := make(chan struct{})
+	go func() {
+		defer close(switched)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.ChangeEndpoint()
+			}
+		}
+	}()
+	results := c.QueryBalancesOfAddresses(addrs, "untrn", 0, 2)
+	close(stop)
+	<-switched
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		if !res.Balances.IsEqual(types.NewCoins(types.NewInt64Coin("untrn", int64(i+1)))) {
+			t.Fatalf("address %d got %s", i, res.Balances)
+		}
+	}
+	if len(heights) != 1 {
+		t.Fatalf("batch read at heights %v", heights)
+	}
+}
+
+func TestGetAllBalancesPinsHeight(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	pages := map[string]*xBankTypes.QueryAllBalancesResponse{
+		"": {
+			Balances:   types.NewCoins(types.NewInt64Coin("uatom", 1)),
+			Pagination: &query.PageResponse{NextKey: []byte("next")},
+		},
+		"next": {
+			Balances:   types.NewCoins(types.NewInt64Coin("untrn", 2)),
+			Pagination: &query.PageResponse{},
+		},
+	}
+	heights := make([]int64, 0)
+	chain.HandleQuery("/cosmos.bank.v1beta1.Query/AllBalances", func(data []byte, height int64) ([]byte, error) {
+		req := xBankTypes.QueryAllBalancesRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+		chain.CommitBlock()
+		return pages[string(req.Pagination.Key)].Marshal()
+	})
+
+	balances, err := c.GetAllBalances(c.GetFromAddress(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balances.IsEqual(types.NewCoins(types.NewInt64Coin("uatom", 1), types.NewInt64Coin("untrn", 2))) {
+		t.Fatalf("got balances %s", balances)
+	}
+	if len(heights) != 2 || heights[0] != heights[1] {
+		t.Fatalf("pages read at heights %v", heights)
+	}
+}
@@ -0,0 +1,398 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	xDistriTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	xSlashingTypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	xStakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// ValidatorSigningInfo is the slashing signing info of a validator with the window it is counted in.
+type ValidatorSigningInfo struct {
+	ValidatorAddress    string
+	ConsAddress         string
+	Info                xSlashingTypes.ValidatorSigningInfo
+	SignedBlocksWindow  int64
+	MinSignedPerWindow  types.Dec
+	MissedBlocksCounter int64
+}
+
+// status is one of xStakeTypes.BondStatusBonded, BondStatusUnbonding, BondStatusUnbonded or empty for all
+func (c *Client) QueryValidators(status string, pageReq *query.PageRequest) (*xStakeTypes.QueryValidatorsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryValidators(status, pageReq, 0)
+}
+
+func (c *Client) queryValidators(status string, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryValidatorsResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(clientCtx)
+		return queryClient.Validators(context.Background(), &xStakeTypes.QueryValidatorsRequest{
+			Status:     status,
+			Pagination: pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := cc.(*xStakeTypes.QueryValidatorsResponse)
+	// the query responses don't unpack the consensus pubkeys
+	if err := xStakeTypes.Validators(res.Validators).UnpackInterfaces(c.Ctx().InterfaceRegistry); err != nil {
+		return nil, fmt.Errorf("unpack validators err: %s", err)
+	}
+	return res, nil
+}
+
+// GetValidators walks all pages of QueryValidators.
+func (c *Client) GetValidators(status string) ([]xStakeTypes.Validator, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	validators := make([]xStakeTypes.Validator, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryValidators(status, pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, res.Validators...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
+func (c *Client) QueryValidator(valAddr types.ValAddress) (*xStakeTypes.QueryValidatorResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryValidator(valAddr.String())
+}
+
+func (c *Client) queryValidator(valAddr string) (*xStakeTypes.QueryValidatorResponse, error) {
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(c.Ctx())
+		return queryClient.Validator(context.Background(), &xStakeTypes.QueryValidatorRequest{ValidatorAddr: valAddr})
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := cc.(*xStakeTypes.QueryValidatorResponse)
+	if err := res.Validator.UnpackInterfaces(c.Ctx().InterfaceRegistry); err != nil {
+		return nil, fmt.Errorf("unpack validator %s err: %s", valAddr, err)
+	}
+	return res, nil
+}
+
+func (c *Client) QueryDelegation(delAddr types.AccAddress, valAddr types.ValAddress, height int64) (*xStakeTypes.QueryDelegationResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(clientCtx)
+		return queryClient.Delegation(context.Background(), &xStakeTypes.QueryDelegationRequest{
+			DelegatorAddr: delAddr.String(),
+			ValidatorAddr: valAddr.String(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xStakeTypes.QueryDelegationResponse), nil
+}
+
+func (c *Client) QueryDelegations(delAddr types.AccAddress, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryDelegatorDelegationsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryDelegations(delAddr.String(), pageReq, height)
+}
+
+func (c *Client) queryDelegations(delAddr string, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryDelegatorDelegationsResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(clientCtx)
+		return queryClient.DelegatorDelegations(context.Background(), &xStakeTypes.QueryDelegatorDelegationsRequest{
+			DelegatorAddr: delAddr,
+			Pagination:    pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xStakeTypes.QueryDelegatorDelegationsResponse), nil
+}
+
+// GetDelegations walks all pages of QueryDelegations.
+func (c *Client) GetDelegations(delAddr types.AccAddress, height int64) (xStakeTypes.DelegationResponses, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	delegations := make(xStakeTypes.DelegationResponses, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryDelegations(delAddr.String(), pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, res.DelegationResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+func (c *Client) QueryUnbondingDelegations(delAddr types.AccAddress, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryDelegatorUnbondingDelegationsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryUnbondingDelegations(delAddr.String(), pageReq, height)
+}
+
+func (c *Client) queryUnbondingDelegations(delAddr string, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryDelegatorUnbondingDelegationsResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(clientCtx)
+		return queryClient.DelegatorUnbondingDelegations(context.Background(), &xStakeTypes.QueryDelegatorUnbondingDelegationsRequest{
+			DelegatorAddr: delAddr,
+			Pagination:    pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xStakeTypes.QueryDelegatorUnbondingDelegationsResponse), nil
+}
+
+// GetUnbondingDelegations walks all pages of QueryUnbondingDelegations.
+func (c *Client) GetUnbondingDelegations(delAddr types.AccAddress, height int64) ([]xStakeTypes.UnbondingDelegation, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	unbondings := make([]xStakeTypes.UnbondingDelegation, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryUnbondingDelegations(delAddr.String(), pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		unbondings = append(unbondings, res.UnbondingResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return unbondings, nil
+}
+
+func (c *Client) QueryRedelegations(delAddr types.AccAddress, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryRedelegationsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.queryRedelegations(delAddr.String(), pageReq, height)
+}
+
+func (c *Client) queryRedelegations(delAddr string, pageReq *query.PageRequest, height int64) (*xStakeTypes.QueryRedelegationsResponse, error) {
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xStakeTypes.NewQueryClient(clientCtx)
+		return queryClient.Redelegations(context.Background(), &xStakeTypes.QueryRedelegationsRequest{
+			DelegatorAddr: delAddr,
+			Pagination:    pageReq,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xStakeTypes.QueryRedelegationsResponse), nil
+}
+
+// GetRedelegations walks all pages of QueryRedelegations.
+func (c *Client) GetRedelegations(delAddr types.AccAddress, height int64) (xStakeTypes.RedelegationResponses, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	height, err := c.pinHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	redelegations := make(xStakeTypes.RedelegationResponses, 0)
+	err = walkPages(func(pageReq *query.PageRequest) (*query.PageResponse, error) {
+		res, err := c.queryRedelegations(delAddr.String(), pageReq, height)
+		if err != nil {
+			return nil, err
+		}
+		redelegations = append(redelegations, res.RedelegationResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redelegations, nil
+}
+
+// QueryDelegationTotalRewards returns the pending rewards of delAddr from every validator.
+func (c *Client) QueryDelegationTotalRewards(delAddr types.AccAddress, height int64) (*xDistriTypes.QueryDelegationTotalRewardsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xDistriTypes.NewQueryClient(clientCtx)
+		return queryClient.DelegationTotalRewards(context.Background(), &xDistriTypes.QueryDelegationTotalRewardsRequest{
+			DelegatorAddress: delAddr.String(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xDistriTypes.QueryDelegationTotalRewardsResponse), nil
+}
+
+func (c *Client) QueryDelegationRewards(delAddr types.AccAddress, valAddr types.ValAddress, height int64) (*xDistriTypes.QueryDelegationRewardsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		queryClient := xDistriTypes.NewQueryClient(clientCtx)
+		return queryClient.DelegationRewards(context.Background(), &xDistriTypes.QueryDelegationRewardsRequest{
+			DelegatorAddress: delAddr.String(),
+			ValidatorAddress: valAddr.String(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xDistriTypes.QueryDelegationRewardsResponse), nil
+}
+
+func (c *Client) QueryValidatorCommission(valAddr types.ValAddress) (*xDistriTypes.QueryValidatorCommissionResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xDistriTypes.NewQueryClient(c.Ctx())
+		return queryClient.ValidatorCommission(context.Background(), &xDistriTypes.QueryValidatorCommissionRequest{
+			ValidatorAddress: valAddr.String(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xDistriTypes.QueryValidatorCommissionResponse), nil
+}
+
+func (c *Client) QueryWithdrawAddress(delAddr types.AccAddress) (*xDistriTypes.QueryDelegatorWithdrawAddressResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xDistriTypes.NewQueryClient(c.Ctx())
+		return queryClient.DelegatorWithdrawAddress(context.Background(), &xDistriTypes.QueryDelegatorWithdrawAddressRequest{
+			DelegatorAddress: delAddr.String(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xDistriTypes.QueryDelegatorWithdrawAddressResponse), nil
+}
+
+func (c *Client) QuerySlashingParams() (*xSlashingTypes.QueryParamsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.querySlashingParams()
+}
+
+func (c *Client) querySlashingParams() (*xSlashingTypes.QueryParamsResponse, error) {
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xSlashingTypes.NewQueryClient(c.Ctx())
+		return queryClient.Params(context.Background(), &xSlashingTypes.QueryParamsRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xSlashingTypes.QueryParamsResponse), nil
+}
+
+func (c *Client) QuerySigningInfo(consAddr types.ConsAddress) (*xSlashingTypes.QuerySigningInfoResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.querySigningInfo(consAddr.String())
+}
+
+func (c *Client) querySigningInfo(consAddr string) (*xSlashingTypes.QuerySigningInfoResponse, error) {
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xSlashingTypes.NewQueryClient(c.Ctx())
+		return queryClient.SigningInfo(context.Background(), &xSlashingTypes.QuerySigningInfoRequest{ConsAddress: consAddr})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xSlashingTypes.QuerySigningInfoResponse), nil
+}
+
+func (c *Client) QuerySigningInfos(pageReq *query.PageRequest) (*xSlashingTypes.QuerySigningInfosResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := xSlashingTypes.NewQueryClient(c.Ctx())
+		return queryClient.SigningInfos(context.Background(), &xSlashingTypes.QuerySigningInfosRequest{Pagination: pageReq})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*xSlashingTypes.QuerySigningInfosResponse), nil
+}
+
+// GetValidatorSigningInfo resolves the consensus address of valAddr and returns its signing
+// info together with the slashing window params needed to interpret the missed block counter.
+func (c *Client) GetValidatorSigningInfo(valAddr types.ValAddress) (*ValidatorSigningInfo, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	validator, err := c.queryValidator(valAddr.String())
+	if err != nil {
+		return nil, err
+	}
+	consAddr, err := validator.Validator.GetConsAddr()
+	if err != nil {
+		return nil, fmt.Errorf("validator %s get cons address err: %s", valAddr.String(), err)
+	}
+	signingInfo, err := c.querySigningInfo(consAddr.String())
+	if err != nil {
+		return nil, err
+	}
+	params, err := c.querySlashingParams()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValidatorSigningInfo{
+		ValidatorAddress:    valAddr.String(),
+		ConsAddress:         consAddr.String(),
+		Info:                signingInfo.ValSigningInfo,
+		SignedBlocksWindow:  params.Params.SignedBlocksWindow,
+		MinSignedPerWindow:  params.Params.MinSignedPerWindow,
+		MissedBlocksCounter: signingInfo.ValSigningInfo.MissedBlocksCounter,
+	}, nil
+}
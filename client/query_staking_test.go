@@ -0,0 +1,178 @@
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types"
+	xSlashingTypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	xStakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func TestGetValidatorSigningInfo(t *testing.T) {
+	c, chain := newMockChainClient(t)
+
+	done := core.UseSdkConfigContext("neutron")
+	pubKey := ed25519.GenPrivKeyFromSecret([]byte("validator")).PubKey()
+	valAddr := types.ValAddress([]byte("validator___________"))
+	validator, err := xStakeTypes.NewValidator(valAddr, pubKey, xStakeTypes.Description{Moniker: "validator"})
+	valAddrStr, consAddrStr := valAddr.String(), types.ConsAddress(pubKey.Address()).String()
+	done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.HandleQuery("/cosmos.staking.v1beta1.Query/Validator", func(data []byte, _ int64) ([]byte, error) {
+		req := xStakeTypes.QueryValidatorRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		if req.ValidatorAddr != valAddrStr {
+			t.Errorf("queried validator %s", req.ValidatorAddr)
+		}
+		return (&xStakeTypes.QueryValidatorResponse{Validator: validator}).Marshal()
+	})
+	chain.HandleQuery("/cosmos.slashing.v1beta1.Query/SigningInfo", func(data []byte, _ int64) ([]byte, error) {
+		req := xSlashingTypes.QuerySigningInfoRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		return (&xSlashingTypes.QuerySigningInfoResponse{ValSigningInfo: xSlashingTypes.ValidatorSigningInfo{
+			Address:             req.ConsAddress,
+			MissedBlocksCounter: 12,
+		}}).Marshal()
+	})
+	chain.HandleQuery("/cosmos.slashing.v1beta1.Query/Params", func([]byte, int64) ([]byte, error) {
+		return (&xSlashingTypes.QueryParamsResponse{Params: xSlashingTypes.DefaultParams()}).Marshal()
+	})
+
+	info, err := c.GetValidatorSigningInfo(valAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ValidatorAddress != valAddrStr || info.ConsAddress != consAddrStr || info.Info.Address != consAddrStr {
+		t.Fatalf("unexpected addresses %+v", info)
+	}
+	if info.MissedBlocksCounter != 12 || info.SignedBlocksWindow != xSlashingTypes.DefaultSignedBlocksWindow ||
+		!info.MinSignedPerWindow.Equal(xSlashingTypes.DefaultMinSignedPerWindow) {
+		t.Fatalf("unexpected signing info %+v", info)
+	}
+}
+
+func TestGetDelegations(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	chain.CommitBlock()
+
+	done := core.UseSdkConfigContext("neutron")
+	delAddr := types.AccAddress([]byte("delegator___________"))
+	delegations := make(xStakeTypes.DelegationResponses, 0, 3)
+	for _, name := range []string{"validator_a_________", "validator_b_________", "validator_c_________"} {
+		delegation := xStakeTypes.NewDelegation(delAddr, types.ValAddress([]byte(name)), types.NewDec(10))
+		delegations = append(delegations, xStakeTypes.NewDelegationResp(delAddr, types.ValAddress([]byte(name)), delegation.Shares, types.NewInt64Coin("untrn", 10)))
+	}
+	done()
+
+	// every page is served at the requested height
+	mutex := sync.Mutex{}
+	heights := make(map[int64]bool)
+	chain.HandleQuery("/cosmos.staking.v1beta1.Query/DelegatorDelegations", func(data []byte, height int64) ([]byte, error) {
+		req := xStakeTypes.QueryDelegatorDelegationsRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		mutex.Lock()
+		heights[height] = true
+		mutex.Unlock()
+		start, end, page := testPage(req.Pagination, len(delegations))
+		return (&xStakeTypes.QueryDelegatorDelegationsResponse{DelegationResponses: delegations[start:end], Pagination: page}).Marshal()
+	})
+
+	got, err := c.GetDelegations(delAddr, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2].Delegation.ValidatorAddress != delegations[2].Delegation.ValidatorAddress {
+		t.Fatalf("unexpected delegations %+v", got)
+	}
+	if len(heights) != 1 || !heights[1] {
+		t.Fatalf("pages served at heights %v, want 1", heights)
+	}
+}
+
+func TestStakingWalksPinHeight(t *testing.T) {
+	c, chain := newMockChainClient(t)
+
+	done := core.UseSdkConfigContext("neutron")
+	delAddr := types.AccAddress([]byte("delegator___________"))
+	validators := make(xStakeTypes.Validators, 0, 3)
+	unbondings := make([]xStakeTypes.UnbondingDelegation, 0, 3)
+	redelegations := make(xStakeTypes.RedelegationResponses, 0, 3)
+	for _, name := range []string{"validator_a_________", "validator_b_________", "validator_c_________"} {
+		valAddr := types.ValAddress([]byte(name))
+		validator, err := xStakeTypes.NewValidator(valAddr, ed25519.GenPrivKeyFromSecret([]byte(name)).PubKey(), xStakeTypes.Description{Moniker: name})
+		if err != nil {
+			done()
+			t.Fatal(err)
+		}
+		validators = append(validators, validator)
+		unbondings = append(unbondings, xStakeTypes.NewUnbondingDelegation(delAddr, valAddr, 1, time.Unix(1700000000, 0), types.NewInt(10), 1))
+		redelegations = append(redelegations, xStakeTypes.NewRedelegationResponse(delAddr, valAddr, valAddr, nil))
+	}
+	done()
+
+	// every page commits a block, so unpinned pages would be read at different heights
+	mutex := sync.Mutex{}
+	heights := make(map[string]map[int64]bool)
+	handle := func(path string, page func(data []byte) ([]byte, error)) {
+		heights[path] = make(map[int64]bool)
+		chain.HandleQuery(path, func(data []byte, height int64) ([]byte, error) {
+			mutex.Lock()
+			heights[path][height] = true
+			mutex.Unlock()
+			chain.CommitBlock()
+			return page(data)
+		})
+	}
+	handle("/cosmos.staking.v1beta1.Query/Validators", func(data []byte) ([]byte, error) {
+		req := xStakeTypes.QueryValidatorsRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		start, end, page := testPage(req.Pagination, len(validators))
+		return (&xStakeTypes.QueryValidatorsResponse{Validators: validators[start:end], Pagination: page}).Marshal()
+	})
+	handle("/cosmos.staking.v1beta1.Query/DelegatorUnbondingDelegations", func(data []byte) ([]byte, error) {
+		req := xStakeTypes.QueryDelegatorUnbondingDelegationsRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		start, end, page := testPage(req.Pagination, len(unbondings))
+		return (&xStakeTypes.QueryDelegatorUnbondingDelegationsResponse{UnbondingResponses: unbondings[start:end], Pagination: page}).Marshal()
+	})
+	handle("/cosmos.staking.v1beta1.Query/Redelegations", func(data []byte) ([]byte, error) {
+		req := xStakeTypes.QueryRedelegationsRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		start, end, page := testPage(req.Pagination, len(redelegations))
+		return (&xStakeTypes.QueryRedelegationsResponse{RedelegationResponses: redelegations[start:end], Pagination: page}).Marshal()
+	})
+
+	if got, err := c.GetValidators(""); err != nil || len(got) != 3 {
+		t.Fatalf("got %d validators: %v", len(got), err)
+	}
+	if got, err := c.GetUnbondingDelegations(delAddr, 0); err != nil || len(got) != 3 {
+		t.Fatalf("got %d unbonding delegations: %v", len(got), err)
+	}
+	if got, err := c.GetRedelegations(delAddr, 0); err != nil || len(got) != 3 {
+		t.Fatalf("got %d redelegations: %v", len(got), err)
+	}
+	for path, pageHeights := range heights {
+		if len(pageHeights) != 1 {
+			t.Errorf("%s pages read at heights %v", path, pageHeights)
+		}
+	}
+}
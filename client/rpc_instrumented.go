@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmTypes "github.com/cometbft/cometbft/types"
+)
+
+var errQueryFailed = errors.New("abci query failed")
+
+// instrumentedRPC observes the rpc methods used by the client, ABCI queries are labeled
+// with their query path, e.g. /cosmwasm.wasm.v1.Query/SmartContractState, and endpoints
+// with their host only.
+type instrumentedRPC struct {
+	rpcClient.Client
+	endpoint string
+	metrics  *clientMetrics
+}
+
+func newInstrumentedRPC(node rpcClient.Client, endpoint string, metrics *clientMetrics) rpcClient.Client {
+	if metrics == nil {
+		return node
+	}
+	return &instrumentedRPC{Client: node, endpoint: endpointLabel(endpoint), metrics: metrics}
+}
+
+func (r *instrumentedRPC) observe(method string, start time.Time, err error) {
+	r.metrics.observeRequest(r.endpoint, method, start, err)
+}
+
+func (r *instrumentedRPC) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*ctypes.ResultABCIQuery, error) {
+	return r.ABCIQueryWithOptions(ctx, path, data, rpcClient.DefaultABCIQueryOptions)
+}
+
+func (r *instrumentedRPC) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcClient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	start := time.Now()
+	res, err := r.Client.ABCIQueryWithOptions(ctx, path, data, opts)
+	// a query error is returned in the response code, not as err
+	if err == nil && res.Response.Code != 0 {
+		r.observe(path, start, errQueryFailed)
+	} else {
+		r.observe(path, start, err)
+	}
+	return res, err
+}
+
+func (r *instrumentedRPC) BroadcastTxSync(ctx context.Context, tx tmTypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	start := time.Now()
+	res, err := r.Client.BroadcastTxSync(ctx, tx)
+	r.observe("broadcast_tx_sync", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) BroadcastTxAsync(ctx context.Context, tx tmTypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	start := time.Now()
+	res, err := r.Client.BroadcastTxAsync(ctx, tx)
+	r.observe("broadcast_tx_async", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	start := time.Now()
+	res, err := r.Client.Status(ctx)
+	r.observe("status", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	start := time.Now()
+	res, err := r.Client.Block(ctx, height)
+	r.observe("block", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	start := time.Now()
+	res, err := r.Client.BlockResults(ctx, height)
+	r.observe("block_results", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	start := time.Now()
+	res, err := r.Client.Tx(ctx, hash, prove)
+	r.observe("tx", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*ctypes.ResultTxSearch, error) {
+	start := time.Now()
+	res, err := r.Client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+	r.observe("tx_search", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	start := time.Now()
+	res, err := r.Client.Validators(ctx, height, page, perPage)
+	r.observe("validators", start, err)
+	return res, err
+}
+
+func (r *instrumentedRPC) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	start := time.Now()
+	res, err := r.Client.Commit(ctx, height)
+	r.observe("commit", start, err)
+	return res, err
+}
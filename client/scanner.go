@@ -0,0 +1,389 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+)
+
+const (
+	defaultScanInterval      = 6 * time.Second
+	defaultMaxBlocksPerRound = 100
+	defaultPrefetchWorkers   = 4
+)
+
+var (
+	ErrScannerStarted     = errors.New("scanner already started")
+	ErrCheckpointMismatch = errors.New("checkpoint block hash mismatch")
+)
+
+// CursorStore persists the last fully processed height of a Scanner.
+type CursorStore interface {
+	utils.Blockstorer
+	TryLoadLatestBlock() (*big.Int, error)
+}
+
+// CheckpointStore is a CursorStore that also keeps the hash and time of the block, e.g. a
+// utils.StreamStore. A Scanner saves a full checkpoint per block to it and verifies the
+// stored block against the chain on Start.
+type CheckpointStore interface {
+	CursorStore
+	LoadCheckpoint() (utils.Checkpoint, bool, error)
+	SaveCheckpoint(cp utils.Checkpoint) error
+}
+
+// TxHandler is called for every tx of a scanned block that matches the handler filter.
+type TxHandler func(height int64, tx *types.TxResponse) error
+
+// ContractEventHandler is called for every contract event of a scanned block that matches the handler filter.
+type ContractEventHandler func(height int64, tx *types.TxResponse, event ContractEvent) error
+
+// EventFilter selects txs by event type and/or emitting contract, empty fields match everything.
+type EventFilter struct {
+	EventType       string
+	ContractAddress string
+}
+
+func (f EventFilter) Match(tx *types.TxResponse) bool {
+	if len(f.EventType) == 0 && len(f.ContractAddress) == 0 {
+		return true
+	}
+	for _, event := range tx.Events {
+		if len(f.EventType) != 0 && event.Type != f.EventType {
+			continue
+		}
+		if len(f.ContractAddress) == 0 {
+			return true
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == xWasmTypes.AttributeKeyContractAddr && attr.Value == f.ContractAddress {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type ScannerConfig struct {
+	// StartHeight is used when the store has no cursor or the cursor is lower
+	StartHeight int64
+	// ConfirmationDepth blocks behind the latest height are not scanned yet
+	ConfirmationDepth int64
+	MaxBlocksPerRound int64
+	PrefetchWorkers   int
+	Interval          time.Duration
+	// UseBlockResults fetches txs through /block and /block_results instead of tx_search,
+	// needed on nodes with tx indexing disabled
+	UseBlockResults bool
+}
+
+type scanHandler struct {
+	filter  EventFilter
+	handler TxHandler
+}
+
+// Scanner walks blocks in order, dispatches their txs to the registered handlers and
+// persists the cursor only after every handler of a block returned without error.
+// A failed block is scanned again in the next round, so handlers must be idempotent.
+type Scanner struct {
+	client   BlockSource
+	store    CursorStore
+	cfg      ScannerConfig
+	logger   log.Logger
+	handlers []scanHandler
+
+	mutex   sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+	// scanMutex serializes rounds of the loop and of direct ScanOnce calls
+	scanMutex sync.Mutex
+}
+
+func NewScanner(c BlockSource, store CursorStore, cfg ScannerConfig, logger log.Logger) (*Scanner, error) {
+	if isNil(c) {
+		return nil, fmt.Errorf("client is nil")
+	}
+	if isNil(store) {
+		return nil, fmt.Errorf("cursor store is nil")
+	}
+	if isNil(logger) {
+		return nil, fmt.Errorf("logger is nil")
+	}
+	if cfg.ConfirmationDepth < 0 {
+		return nil, fmt.Errorf("confirmation depth must not be negative")
+	}
+	if cfg.MaxBlocksPerRound <= 0 {
+		cfg.MaxBlocksPerRound = defaultMaxBlocksPerRound
+	}
+	if cfg.PrefetchWorkers <= 0 {
+		cfg.PrefetchWorkers = defaultPrefetchWorkers
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultScanInterval
+	}
+
+	return &Scanner{
+		client: c,
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+// AddHandler registers h for txs matching filter, handlers run in registration order.
+// It must be called before Start.
+func (s *Scanner) AddHandler(filter EventFilter, h TxHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers = append(s.handlers, scanHandler{filter: filter, handler: h})
+}
+
+// AddContractEventHandler registers h for decoded contract events matching filter.
+// It must be called before Start.
+func (s *Scanner) AddContractEventHandler(filter EventFilter, h ContractEventHandler) {
+	s.AddHandler(filter, func(height int64, tx *types.TxResponse) error {
+		for _, event := range FilterContractEvents(DecodeContractEvents(tx), filter) {
+			if err := h(height, tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Start runs the scan loop. On a CheckpointStore it first returns ErrCheckpointMismatch if
+// the chain has another block at the stored checkpoint height.
+func (s *Scanner) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started {
+		return ErrScannerStarted
+	}
+	if err := s.verifyCheckpoint(); err != nil {
+		return err
+	}
+	s.started = true
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.run()
+	return nil
+}
+
+// Stop signals the scan loop to exit and waits for the current round to finish.
+func (s *Scanner) Stop() {
+	s.mutex.Lock()
+	if !s.started {
+		s.mutex.Unlock()
+		return
+	}
+	s.started = false
+	close(s.stop)
+	done := s.done
+	s.mutex.Unlock()
+
+	<-done
+}
+
+func (s *Scanner) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ScanOnce(); err != nil {
+			s.logger.Warn("scanner round failed", "err", err)
+		}
+		select {
+		case <-s.stop:
+			s.logger.Info("scanner stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// NextHeight returns the first height that has not been processed yet.
+func (s *Scanner) NextHeight() (int64, error) {
+	latest, err := s.store.TryLoadLatestBlock()
+	if err != nil {
+		return 0, err
+	}
+	if latest == nil {
+		return 0, fmt.Errorf("cursor store returned nil block")
+	}
+	next := latest.Int64() + 1
+	if s.cfg.StartHeight > next {
+		next = s.cfg.StartHeight
+	}
+	return next, nil
+}
+
+// ScanOnce processes at most MaxBlocksPerRound confirmed blocks and returns the last processed height.
+// It may be called while the loop of Start runs, it waits for the current round then.
+func (s *Scanner) ScanOnce() (int64, error) {
+	s.scanMutex.Lock()
+	defer s.scanMutex.Unlock()
+
+	next, err := s.NextHeight()
+	if err != nil {
+		return 0, err
+	}
+	current, err := s.client.GetCurrentBlockHeight()
+	if err != nil {
+		return next - 1, err
+	}
+	end := current - s.cfg.ConfirmationDepth
+	if end-next+1 > s.cfg.MaxBlocksPerRound {
+		end = next + s.cfg.MaxBlocksPerRound - 1
+	}
+	if end < next {
+		return next - 1, nil
+	}
+
+	results := s.prefetch(next, end)
+	for i, res := range results {
+		height := next + int64(i)
+		if res.err != nil {
+			return height - 1, fmt.Errorf("get block %d txs err: %s", height, res.err)
+		}
+		if err := s.dispatch(height, res.txs); err != nil {
+			return height - 1, err
+		}
+		if err := s.storeBlock(height, res.block); err != nil {
+			return height - 1, fmt.Errorf("store block %d err: %s", height, err)
+		}
+	}
+	return end, nil
+}
+
+func (s *Scanner) storeBlock(height int64, block *ctypes.ResultBlock) error {
+	if cs, ok := s.store.(CheckpointStore); ok {
+		return cs.SaveCheckpoint(checkpointOf(block))
+	}
+	return s.store.StoreBlock(big.NewInt(height))
+}
+
+func (s *Scanner) verifyCheckpoint() error {
+	cs, ok := s.store.(CheckpointStore)
+	if !ok {
+		return nil
+	}
+	cp, found, err := cs.LoadCheckpoint()
+	if err != nil || !found {
+		return err
+	}
+	return verifyCheckpoint(s.client, cp)
+}
+
+type prefetchResult struct {
+	txs   []*types.TxResponse
+	block *ctypes.ResultBlock
+	err   error
+}
+
+// prefetch fetches [start, end] with a bounded number of workers, results keep height order.
+func (s *Scanner) prefetch(start, end int64) []prefetchResult {
+	results := make([]prefetchResult, end-start+1)
+	heights := make(chan int64)
+	wg := sync.WaitGroup{}
+	for w := 0; w < s.cfg.PrefetchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				results[height-start] = s.fetch(height)
+			}
+		}()
+	}
+	for height := start; height <= end; height++ {
+		heights <- height
+	}
+	close(heights)
+	wg.Wait()
+	return results
+}
+
+// fetch gets the txs of height, and the block for its checkpoint on a CheckpointStore.
+func (s *Scanner) fetch(height int64) prefetchResult {
+	txs, err := s.getBlockTxs(height)
+	if err != nil {
+		return prefetchResult{err: err}
+	}
+	res := prefetchResult{txs: txs}
+	if _, ok := s.store.(CheckpointStore); ok {
+		res.block, res.err = s.client.QueryBlock(height)
+	}
+	return res
+}
+
+func (s *Scanner) getBlockTxs(height int64) ([]*types.TxResponse, error) {
+	if s.cfg.UseBlockResults {
+		return s.client.GetBlockTxsByBlockResults(height)
+	}
+	return s.client.GetBlockTxsWithParseErrSkip(height)
+}
+
+func (s *Scanner) dispatch(height int64, txs []*types.TxResponse) error {
+	s.mutex.Lock()
+	handlers := s.handlers
+	s.mutex.Unlock()
+
+	for _, tx := range txs {
+		for _, h := range handlers {
+			if !h.filter.Match(tx) {
+				continue
+			}
+			if err := h.handler(height, tx); err != nil {
+				return fmt.Errorf("handle tx %s at height %d err: %s", tx.TxHash, height, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckpointAt returns a checkpoint of the block at height with its hash and time.
+func (c *Client) CheckpointAt(height int64) (utils.Checkpoint, error) {
+	block, err := c.QueryBlock(height)
+	if err != nil {
+		return utils.Checkpoint{}, err
+	}
+	return checkpointOf(block), nil
+}
+
+// VerifyCheckpoint returns ErrCheckpointMismatch if the chain has another block at the
+// checkpoint height, checkpoints without a block hash are not verified.
+func (c *Client) VerifyCheckpoint(cp utils.Checkpoint) error {
+	return verifyCheckpoint(c, cp)
+}
+
+func verifyCheckpoint(c BlockSource, cp utils.Checkpoint) error {
+	if len(cp.BlockHash) == 0 || cp.Height <= 0 {
+		return nil
+	}
+	block, err := c.QueryBlock(cp.Height)
+	if err != nil {
+		return err
+	}
+	if current := checkpointOf(block); current.BlockHash != cp.BlockHash {
+		return fmt.Errorf("%w: height %d stored %s, chain %s", ErrCheckpointMismatch, cp.Height, cp.BlockHash, current.BlockHash)
+	}
+	return nil
+}
+
+func checkpointOf(block *ctypes.ResultBlock) utils.Checkpoint {
+	return utils.Checkpoint{
+		Height:    block.Block.Height,
+		BlockHash: block.BlockID.Hash.String(),
+		BlockTime: block.Block.Time,
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+)
+
+func TestEventFilterMatch(t *testing.T) {
+	tx := &types.TxResponse{
+		Events: []abci.Event{
+			{Type: "message", Attributes: []abci.EventAttribute{{Key: "action", Value: "/cosmwasm.wasm.v1.MsgExecuteContract"}}},
+			{Type: "wasm", Attributes: []abci.EventAttribute{{Key: "_contract_address", Value: "neutron1pool"}}},
+		},
+	}
+
+	cases := []struct {
+		filter EventFilter
+		want   bool
+	}{
+		{EventFilter{}, true},
+		{EventFilter{EventType: "wasm"}, true},
+		{EventFilter{EventType: "wasm-era_update"}, false},
+		{EventFilter{ContractAddress: "neutron1pool"}, true},
+		{EventFilter{ContractAddress: "neutron1other"}, false},
+		{EventFilter{EventType: "wasm", ContractAddress: "neutron1pool"}, true},
+		{EventFilter{EventType: "message", ContractAddress: "neutron1pool"}, false},
+	}
+	for _, c := range cases {
+		if got := c.filter.Match(tx); got != c.want {
+			t.Errorf("filter %+v: got %v, want %v", c.filter, got, c.want)
+		}
+	}
+}
+
+func TestScannerCheckpoint(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	chain.CommitBlock()
+	chain.CommitBlock()
+	bs, err := utils.NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := utils.NewStateStore(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := state.Stream(chain.ChainId(), "pool")
+	logger := log.NewLog("client", "scanner")
+
+	scanner, err := NewScanner(c, store, ScannerConfig{StartHeight: 1, Interval: time.Hour}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := scanner.ScanOnce()
+	if err != nil || last != 3 {
+		t.Fatalf("scanned to %d: %v", last, err)
+	}
+	cp, found, err := store.LoadCheckpoint()
+	if err != nil || !found {
+		t.Fatalf("no checkpoint saved: %v", err)
+	}
+	block, err := c.QueryBlock(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Height != 3 || cp.BlockHash != block.BlockID.Hash.String() || !cp.BlockTime.Equal(block.Block.Time) {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+	if err := scanner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	scanner.Stop()
+
+	// the stored block is not the block of the chain at its height
+	cp.BlockHash = strings.Repeat("AB", 32)
+	if err := store.SaveCheckpoint(cp); err != nil {
+		t.Fatal(err)
+	}
+	if err := scanner.Start(); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Fatalf("got %v, want checkpoint mismatch", err)
+	}
+}
+
+func TestScannerScanOnceWhileStarted(t *testing.T) {
+	c, _ := newMockChainClient(t)
+	for i := 0; i < 3; i++ {
+		if _, err := c.SendContractExecuteMsg(failoverContract, []byte(`{"era_update":{}}`), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bs, err := utils.NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := NewScanner(c, bs, ScannerConfig{StartHeight: 1, Interval: time.Hour}, log.NewLog("client", "scanner"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutex := sync.Mutex{}
+	// the txs share their hash, the sequence of the test account doesn't move
+	handled := make(map[int64]int)
+	scanner.AddHandler(EventFilter{}, func(height int64, _ *types.TxResponse) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		handled[height]++
+		return nil
+	})
+
+	// direct rounds run concurrently with the first round of the loop
+	if err := scanner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := scanner.ScanOnce(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	scanner.Stop()
+
+	if len(handled) != 3 {
+		t.Fatalf("handled %d txs, want 3", len(handled))
+	}
+	for height, count := range handled {
+		if count != 1 {
+			t.Errorf("tx at height %d handled %d times", height, count)
+		}
+	}
+}
+
+func TestNewScannerTypedNil(t *testing.T) {
+	bs, err := utils.NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewLog("client", "scanner")
+	if _, err := NewScanner((*Client)(nil), bs, ScannerConfig{}, logger); err == nil {
+		t.Fatal("nil client accepted")
+	}
+	if _, err := NewScanner(&Client{}, (*utils.Blockstore)(nil), ScannerConfig{}, logger); err == nil {
+		t.Fatal("nil store accepted")
+	}
+}
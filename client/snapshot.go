@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	xStakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// Snapshot runs queries against one height of one endpoint, so results of several contracts
+// and modules are consistent with each other. If the pinned endpoint becomes unreachable
+// the snapshot moves to another endpoint retaining the same height, state at a fixed height
+// is identical on every node. A Snapshot is safe for concurrent use.
+type Snapshot struct {
+	client    *Client
+	Height    int64
+	BlockTime time.Time
+
+	mutex         sync.Mutex
+	clientCtx     client.Context
+	endpointIndex int
+}
+
+// SnapshotQuery is a single query of a snapshot batch.
+type SnapshotQuery struct {
+	Name string
+	run  func(clientCtx client.Context) (interface{}, error)
+}
+
+type SnapshotResult struct {
+	Height        int64
+	BlockTime     time.Time
+	EndpointIndex int
+	// Responses keep the order of the queries passed to Run
+	Responses []interface{}
+}
+
+// NewSnapshot pins height, or the latest height of the current endpoint when height is 0.
+func (c *Client) NewSnapshot(height int64) (*Snapshot, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	candidates := make([]int, 0, len(c.rpcClientList))
+	for j := 0; j < len(c.rpcClientList); j++ {
+		candidates = append(candidates, (c.CurrentEndpointIndex()+j)%len(c.rpcClientList))
+	}
+	if height > 0 {
+		candidates = c.heightCandidates(height)
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%w: height %d", ErrHeightPruned, height)
+		}
+	}
+
+	var err error
+	for _, index := range candidates {
+		var snapshot *Snapshot
+		snapshot, err = c.pinSnapshot(index, height)
+		if err == nil {
+			return snapshot, nil
+		}
+		c.logger.Debug("pin snapshot failed", "endpoint index", index, "height", height, "err", err)
+	}
+	return nil, err
+}
+
+func (c *Client) pinSnapshot(index int, height int64) (*Snapshot, error) {
+	node := c.rpcClientList[index]
+	var blockHeight *int64
+	if height > 0 {
+		blockHeight = &height
+	}
+	block, err := node.Block(context.Background(), blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		client:        c,
+		clientCtx:     c.abciCtx().WithClient(node).WithHeight(block.Block.Height),
+		endpointIndex: index,
+		Height:        block.Block.Height,
+		BlockTime:     block.Block.Time,
+	}, nil
+}
+
+// EndpointIndex returns the index of the endpoint the snapshot currently queries.
+func (s *Snapshot) EndpointIndex() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.endpointIndex
+}
+
+// Run executes queries in order and stops at the first error.
+func (s *Snapshot) Run(queries ...SnapshotQuery) (*SnapshotResult, error) {
+	done := core.UseSdkConfigContext(s.client.GetAccountPrefix())
+	defer done()
+
+	responses := make([]interface{}, len(queries))
+	for i, query := range queries {
+		res, err := s.query(query.run)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot query %d %s at height %d err: %w", i, query.Name, s.Height, err)
+		}
+		responses[i] = res
+	}
+	return &SnapshotResult{
+		Height:        s.Height,
+		BlockTime:     s.BlockTime,
+		EndpointIndex: s.EndpointIndex(),
+		Responses:     responses,
+	}, nil
+}
+
+// query runs f without holding the mutex, concurrent queries that fail over at the same
+// time each move the snapshot to the endpoint that answered them.
+func (s *Snapshot) query(f func(clientCtx client.Context) (interface{}, error)) (interface{}, error) {
+	s.mutex.Lock()
+	current, currentIndex := s.clientCtx, s.endpointIndex
+	s.mutex.Unlock()
+
+	res, err := f(current)
+	if err == nil || !isConnectionError(err) {
+		return res, err
+	}
+
+	for _, index := range s.client.heightCandidates(s.Height) {
+		if index == currentIndex {
+			continue
+		}
+		clientCtx := current.WithClient(s.client.rpcClientList[index])
+		res, err = f(clientCtx)
+		if err == nil {
+			s.mutex.Lock()
+			s.clientCtx = clientCtx
+			s.endpointIndex = index
+			s.mutex.Unlock()
+			return res, nil
+		}
+		if !isConnectionError(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (s *Snapshot) QuerySmartContractState(contract string, req []byte) (*xWasmTypes.QuerySmartContractStateResponse, error) {
+	result, err := s.Run(SmartContractStateQuery(contract, req))
+	if err != nil {
+		return nil, err
+	}
+	return result.Responses[0].(*xWasmTypes.QuerySmartContractStateResponse), nil
+}
+
+func (s *Snapshot) QueryBalance(addr types.AccAddress, denom string) (*xBankTypes.QueryBalanceResponse, error) {
+	result, err := s.Run(BalanceQuery(addr, denom))
+	if err != nil {
+		return nil, err
+	}
+	return result.Responses[0].(*xBankTypes.QueryBalanceResponse), nil
+}
+
+// QueryBlockResults returns the block results of the pinned height from the pinned endpoint.
+func (s *Snapshot) QueryBlockResults() (*ctypes.ResultBlockResults, error) {
+	result, err := s.Run(SnapshotQuery{
+		Name: "block_results",
+		run: func(clientCtx client.Context) (interface{}, error) {
+			node, ok := clientCtx.Client.(rpcClient.SignClient)
+			if !ok {
+				return nil, fmt.Errorf("rpc client doesn't support block results")
+			}
+			return node.BlockResults(context.Background(), &clientCtx.Height)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Responses[0].(*ctypes.ResultBlockResults), nil
+}
+
+// SmartContractStateQuery returns *xWasmTypes.QuerySmartContractStateResponse
+func SmartContractStateQuery(contract string, req []byte) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "wasm smart " + contract,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xWasmTypes.NewQueryClient(clientCtx).SmartContractState(context.Background(), &xWasmTypes.QuerySmartContractStateRequest{
+				Address:   contract,
+				QueryData: req,
+			})
+		},
+	}
+}
+
+// RawContractStateQuery returns *xWasmTypes.QueryRawContractStateResponse
+func RawContractStateQuery(contract string, key []byte) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "wasm raw " + contract,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xWasmTypes.NewQueryClient(clientCtx).RawContractState(context.Background(), &xWasmTypes.QueryRawContractStateRequest{
+				Address:   contract,
+				QueryData: key,
+			})
+		},
+	}
+}
+
+// BalanceQuery returns *xBankTypes.QueryBalanceResponse
+func BalanceQuery(addr types.AccAddress, denom string) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "bank balance " + denom,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xBankTypes.NewQueryClient(clientCtx).Balance(context.Background(), xBankTypes.NewQueryBalanceRequest(addr, denom))
+		},
+	}
+}
+
+// SupplyOfQuery returns *xBankTypes.QuerySupplyOfResponse
+func SupplyOfQuery(denom string) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "bank supply " + denom,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xBankTypes.NewQueryClient(clientCtx).SupplyOf(context.Background(), &xBankTypes.QuerySupplyOfRequest{Denom: denom})
+		},
+	}
+}
+
+// DelegationQuery returns *xStakeTypes.QueryDelegationResponse
+func DelegationQuery(delegator string, validator string) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "staking delegation " + delegator,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xStakeTypes.NewQueryClient(clientCtx).Delegation(context.Background(), &xStakeTypes.QueryDelegationRequest{
+				DelegatorAddr: delegator,
+				ValidatorAddr: validator,
+			})
+		},
+	}
+}
+
+// DelegatorDelegationsQuery returns *xStakeTypes.QueryDelegatorDelegationsResponse
+func DelegatorDelegationsQuery(delegator string) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "staking delegator delegations " + delegator,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xStakeTypes.NewQueryClient(clientCtx).DelegatorDelegations(context.Background(), &xStakeTypes.QueryDelegatorDelegationsRequest{
+				DelegatorAddr: delegator,
+			})
+		},
+	}
+}
+
+// ValidatorQuery returns *xStakeTypes.QueryValidatorResponse
+func ValidatorQuery(validator string) SnapshotQuery {
+	return SnapshotQuery{
+		Name: "staking validator " + validator,
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xStakeTypes.NewQueryClient(clientCtx).Validator(context.Background(), &xStakeTypes.QueryValidatorRequest{
+				ValidatorAddr: validator,
+			})
+		},
+	}
+}
+
+// StakingPoolQuery returns *xStakeTypes.QueryPoolResponse
+func StakingPoolQuery() SnapshotQuery {
+	return SnapshotQuery{
+		Name: "staking pool",
+		run: func(clientCtx client.Context) (interface{}, error) {
+			return xStakeTypes.NewQueryClient(clientCtx).Pool(context.Background(), &xStakeTypes.QueryPoolRequest{})
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"sync"
+	"testing"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+)
+
+func TestSnapshotFailover(t *testing.T) {
+	c, proxy, chain := newFailoverClient(t)
+	snapshot, err := c.NewSnapshot(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.EndpointIndex() != 0 || snapshot.Height != chain.Height() {
+		t.Fatalf("pinned endpoint %d height %d", snapshot.EndpointIndex(), snapshot.Height)
+	}
+	chain.CommitBlock()
+	if err := proxy.SetFault(clienttest.FaultConnectionRefused); err != nil {
+		t.Fatal(err)
+	}
+
+	// queries and readers of the endpoint index run concurrently with the failover
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 8)
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			result, err := snapshot.Run(SmartContractStateQuery(failoverContract, []byte(`{"era":{}}`)))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if string(result.Responses[0].(*xWasmTypes.QuerySmartContractStateResponse).Data) != `{"era":12}` {
+				t.Errorf("unexpected response %+v", result.Responses[0])
+			}
+			if result.Height != snapshot.Height {
+				t.Errorf("result at height %d, snapshot at %d", result.Height, snapshot.Height)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			snapshot.EndpointIndex()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if snapshot.EndpointIndex() != 1 {
+		t.Fatalf("endpoint index %d, want failover to 1", snapshot.EndpointIndex())
+	}
+}
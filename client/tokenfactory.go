@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	tokenfactoryTypes "github.com/neutron-org/neutron/v2/x/tokenfactory/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// GetTokenfactoryDenom returns the full denom factory/{creator}/{subdenom}.
+func GetTokenfactoryDenom(creator, subdenom string) (string, error) {
+	return tokenfactoryTypes.GetTokenDenom(creator, subdenom)
+}
+
+func (c *Client) QueryDenomAuthorityMetadata(creator, subdenom string) (*tokenfactoryTypes.DenomAuthorityMetadata, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := tokenfactoryTypes.NewQueryClient(c.Ctx())
+		return queryClient.DenomAuthorityMetadata(context.Background(), &tokenfactoryTypes.QueryDenomAuthorityMetadataRequest{
+			Creator:  creator,
+			Subdenom: subdenom,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cc.(*tokenfactoryTypes.QueryDenomAuthorityMetadataResponse).AuthorityMetadata, nil
+}
+
+func (c *Client) QueryDenomsFromCreator(creator string) ([]string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := tokenfactoryTypes.NewQueryClient(c.Ctx())
+		return queryClient.DenomsFromCreator(context.Background(), &tokenfactoryTypes.QueryDenomsFromCreatorRequest{Creator: creator})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*tokenfactoryTypes.QueryDenomsFromCreatorResponse).Denoms, nil
+}
+
+// QueryBeforeSendHookAddress returns the contract called before sends of the denom, empty if unset.
+func (c *Client) QueryBeforeSendHookAddress(creator, subdenom string) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := tokenfactoryTypes.NewQueryClient(c.Ctx())
+		return queryClient.BeforeSendHookAddress(context.Background(), &tokenfactoryTypes.QueryBeforeSendHookAddressRequest{
+			Creator:  creator,
+			Subdenom: subdenom,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return cc.(*tokenfactoryTypes.QueryBeforeSendHookAddressResponse).ContractAddr, nil
+}
+
+func (c *Client) QueryTokenfactoryParams() (*tokenfactoryTypes.QueryParamsResponse, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retry(func() (interface{}, error) {
+		queryClient := tokenfactoryTypes.NewQueryClient(c.Ctx())
+		return queryClient.Params(context.Background(), &tokenfactoryTypes.QueryParamsRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.(*tokenfactoryTypes.QueryParamsResponse), nil
+}
+
+// CreateDenom creates factory/{from}/{subdenom} and returns the denom with the tx hash.
+func (c *Client) CreateDenom(subdenom string) (string, string, error) {
+	sender := c.fromAddressString()
+	denom, err := tokenfactoryTypes.GetTokenDenom(sender, subdenom)
+	if err != nil {
+		return "", "", err
+	}
+	txHash, err := c.sendTokenfactoryMsg(tokenfactoryTypes.NewMsgCreateDenom(sender, subdenom))
+	if err != nil {
+		return "", txHash, err
+	}
+	return denom, txHash, nil
+}
+
+// Mint mints amount of a denom administered by the from account to mintTo, or to the from
+// account when mintTo is empty.
+func (c *Client) Mint(amount types.Coin, mintTo string) (string, error) {
+	sender := c.fromAddressString()
+	msg := tokenfactoryTypes.NewMsgMint(sender, amount)
+	if len(mintTo) != 0 {
+		msg = tokenfactoryTypes.NewMsgMintTo(sender, amount, mintTo)
+	}
+	return c.sendTokenfactoryMsg(msg)
+}
+
+func (c *Client) Burn(amount types.Coin) (string, error) {
+	return c.sendTokenfactoryMsg(tokenfactoryTypes.NewMsgBurn(c.fromAddressString(), amount))
+}
+
+func (c *Client) ChangeDenomAdmin(denom, newAdmin string) (string, error) {
+	return c.sendTokenfactoryMsg(tokenfactoryTypes.NewMsgChangeAdmin(c.fromAddressString(), denom, newAdmin))
+}
+
+func (c *Client) SetDenomMetadata(metadata xBankTypes.Metadata) (string, error) {
+	return c.sendTokenfactoryMsg(tokenfactoryTypes.NewMsgSetDenomMetadata(c.fromAddressString(), metadata))
+}
+
+// SetBeforeSendHook sets the contract called before sends of denom, an empty contractAddr removes it.
+func (c *Client) SetBeforeSendHook(denom, contractAddr string) (string, error) {
+	return c.sendTokenfactoryMsg(tokenfactoryTypes.NewMsgSetBeforeSendHook(c.fromAddressString(), denom, contractAddr))
+}
+
+func (c *Client) sendTokenfactoryMsg(msg types.Msg) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	err := msg.ValidateBasic()
+	done()
+	if err != nil {
+		return "", err
+	}
+
+	txBts, err := c.ConstructAndSignTx(msg)
+	if err != nil {
+		return "", err
+	}
+	return c.BroadcastTx(txBts)
+}
+
+func (c *Client) fromAddressString() string {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.Ctx().GetFromAddress().String()
+}
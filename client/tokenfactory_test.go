@@ -0,0 +1,53 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	tokenfactoryTypes "github.com/neutron-org/neutron/v2/x/tokenfactory/types"
+)
+
+func TestCreateDenom(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	creator := c.fromAddressString()
+
+	denom, _, err := c.CreateDenom("rtoken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denom != "factory/"+creator+"/rtoken" {
+		t.Fatalf("unexpected denom %s", denom)
+	}
+	msgs := broadcastMsgs(t, c, chain)
+	create, ok := msgs[0].(*tokenfactoryTypes.MsgCreateDenom)
+	if !ok || create.Sender != creator || create.Subdenom != "rtoken" {
+		t.Fatalf("broadcast %v", msgs[0])
+	}
+
+	// invalid msgs are rejected before signing
+	if _, err := c.Mint(types.NewInt64Coin(denom, 0), ""); err == nil {
+		t.Fatal("expected invalid amount error")
+	}
+	if len(chain.BroadcastTxs()) != 1 {
+		t.Fatal("invalid mint was broadcast")
+	}
+
+	chain.HandleQuery("/osmosis.tokenfactory.v1beta1.Query/DenomsFromCreator", func(data []byte, _ int64) ([]byte, error) {
+		req := tokenfactoryTypes.QueryDenomsFromCreatorRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		res := &tokenfactoryTypes.QueryDenomsFromCreatorResponse{}
+		if req.Creator == creator {
+			res.Denoms = []string{denom}
+		}
+		return res.Marshal()
+	})
+	denoms, err := c.QueryDenomsFromCreator(creator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(denoms) != 1 || denoms[0] != denom {
+		t.Fatalf("unexpected denoms %v", denoms)
+	}
+}
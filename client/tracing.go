@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/stafihub/neutron-relay-sdk/client"
+
+// Span attribute keys
+const (
+	AttrEndpoint  = attribute.Key("neutron.endpoint")
+	AttrAttempt   = attribute.Key("neutron.retry.attempt")
+	AttrHeight    = attribute.Key("neutron.height")
+	AttrContract  = attribute.Key("neutron.contract")
+	AttrTxHash    = attribute.Key("neutron.tx.hash")
+	AttrErrorKind = attribute.Key("neutron.error.kind")
+)
+
+// WithTracerProvider creates client spans with tp, without it spans are no-ops.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) error {
+		c.tracer = tp.Tracer(tracerName)
+		return nil
+	}
+}
+
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err with its kind: connection, height_pruned or business.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(AttrErrorKind.String(errorKind(err)))
+	}
+	span.End()
+}
+
+func errorKind(err error) string {
+	switch {
+	case isConnectionError(err):
+		return "connection"
+	case isHeightPrunedError(err):
+		return "height_pruned"
+	default:
+		return "business"
+	}
+}
+
+func (c *Client) endpointUrl(index int) string {
+	if c.endpoints == nil || index < 0 || index >= len(c.endpoints.infos) {
+		return ""
+	}
+	return c.endpoints.get(index).Url
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRetrySpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	c := &Client{
+		rpcClientList: []rpcClient.Client{nil, nil},
+		endpoints:     newEndpointRegistry([]string{"http://a", "http://b"}),
+		logger:        log.NewLog("client", "test"),
+	}
+	if err := WithTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))(c); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := c.startSpan(context.Background(), "parent")
+	calls := 0
+	_, err := c.retryWithContext(ctx, func(context.Context) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("contract not found")
+		}
+		return "ok", nil
+	})
+	parent.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	first, second := spans[0], spans[1]
+	if first.Parent().SpanID() != parent.SpanContext().SpanID() || second.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("attempt spans are not children of the caller span")
+	}
+	attrs := make(map[string]string)
+	for _, attr := range first.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs[string(AttrEndpoint)] != "http://a" || attrs[string(AttrErrorKind)] != "business" {
+		t.Fatalf("unexpected first attempt attrs %v", attrs)
+	}
+	for _, attr := range second.Attributes() {
+		if attr.Key == AttrEndpoint && attr.Value.AsString() != "http://b" {
+			t.Fatalf("second attempt on %s", attr.Value.AsString())
+		}
+	}
+}
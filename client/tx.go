@@ -1,29 +1,24 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	tmTypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
 	clientTx "github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	xAuthClient "github.com/cosmos/cosmos-sdk/x/auth/client"
-	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 
 	"github.com/spf13/cobra"
 	"github.com/stafihub/rtoken-relay-core/common/core"
 )
 
-func (c *Client) SingleTransferTo(toAddr types.AccAddress, amount types.Coins) error {
-	done := core.UseSdkConfigContext(c.GetAccountPrefix())
-	defer done()
-
-	msg := xBankTypes.NewMsgSend(c.Ctx().GetFromAddress(), toAddr, amount)
-	cmd := cobra.Command{}
-	return clientTx.GenerateOrBroadcastTxCLI(c.Ctx(), cmd.Flags(), msg)
-}
-
 func (c *Client) SendContractExecuteMsg(contract string, msg []byte, amount types.Coins) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	msgs := []types.Msg{
 		&xWasmTypes.MsgExecuteContract{
 			Sender:   c.clientCtx.FromAddress.String(),
@@ -32,6 +27,7 @@ func (c *Client) SendContractExecuteMsg(contract string, msg []byte, amount type
 			Funds:    amount,
 		},
 	}
+	done()
 
 	txbts, err := c.ConstructAndSignTx(msgs...)
 	if err != nil {
@@ -47,31 +43,60 @@ func (c *Client) SendContractExecuteMsg(contract string, msg []byte, amount type
 }
 
 func (c *Client) BroadcastTx(tx []byte) (string, error) {
-	done := core.UseSdkConfigContext(c.GetAccountPrefix())
-	defer done()
+	return c.BroadcastTxWithContext(context.Background(), tx)
+}
 
-	cc, err := c.retry(func() (interface{}, error) {
-		return c.Ctx().BroadcastTx(tx)
-	})
+func (c *Client) BroadcastTxWithContext(ctx context.Context, tx []byte) (string, error) {
+	res, err := c.BroadcastTxResponse(ctx, tx)
 	if err != nil {
-		return "", fmt.Errorf("retry broadcastTx err: %s", err)
+		return "", err
 	}
-	res := cc.(*types.TxResponse)
 	if res.Code != 0 {
 		return res.TxHash, fmt.Errorf("broadcast err with res.code: %d, res.Codespace: %s", res.Code, res.Codespace)
 	}
 	return res.TxHash, nil
 }
 
-func (c *Client) ConstructAndSignTx(msgs ...types.Msg) ([]byte, error) {
-	account, err := c.GetAccount()
+// BroadcastTxResponse broadcasts tx and returns the CheckTx response, a rejected tx is not
+// an error here and must be told by res.Code.
+func (c *Client) BroadcastTxResponse(ctx context.Context, tx []byte) (res *types.TxResponse, err error) {
+	ctx, span := c.startSpan(ctx, "BroadcastTx", AttrTxHash.String(fmt.Sprintf("%X", tmTypes.Tx(tx).Hash())))
+	defer func() { endSpan(span, err) }()
+
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	cc, err := c.retryWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return broadcastTxSync(ctx, c.Ctx(), tx)
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("retry broadcastTx err: %s", err)
 	}
+	res = cc.(*types.TxResponse)
+	c.metrics.observeBroadcast(res.Code, res.Codespace)
+	return res, nil
+}
+
+func (c *Client) ConstructAndSignTx(msgs ...types.Msg) ([]byte, error) {
+	return c.ConstructAndSignTxWithMemo("", msgs...)
+}
+
+func (c *Client) ConstructAndSignTxWithMemo(memo string, msgs ...types.Msg) ([]byte, error) {
+	return c.ConstructAndSignTxWithContext(context.Background(), memo, msgs...)
+}
+
+func (c *Client) ConstructAndSignTxWithContext(ctx context.Context, memo string, msgs ...types.Msg) (txBts []byte, err error) {
+	ctx, span := c.startSpan(ctx, "ConstructAndSignTx")
+	defer func() { endSpan(span, err) }()
+
 	done := core.UseSdkConfigContext(c.GetAccountPrefix())
 	defer done()
 
 	clientCtx := c.Ctx()
+	account, err := c.getAccountWithContext(ctx, clientCtx.FromAddress)
+	if err != nil {
+		return nil, err
+	}
 	cmd := cobra.Command{}
 	txf, err := clientTx.NewFactoryCLI(clientCtx, cmd.Flags())
 	if err != nil {
@@ -83,14 +108,16 @@ func (c *Client) ConstructAndSignTx(msgs ...types.Msg) ([]byte, error) {
 		WithGasAdjustment(1.5).
 		WithGas(0).
 		WithGasPrices(c.gasPrice).
+		WithMemo(memo).
 		WithSimulateAndExecute(true)
 
 	// auto cal gas with retry
-	adjusted, err := c.CalculateGas(txf, msgs...)
+	adjusted, err := c.CalculateGasWithContext(ctx, txf, msgs...)
 	if err != nil {
 		return nil, err
 	}
 	txf = txf.WithGas(adjusted)
+	c.metrics.observeGasEstimated(adjusted, account.GetSequence())
 
 	txBuilderRaw, err := txf.BuildUnsignedTx(msgs...)
 	if err != nil {
@@ -106,13 +133,20 @@ func (c *Client) ConstructAndSignTx(msgs ...types.Msg) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(AttrTxHash.String(fmt.Sprintf("%X", tmTypes.Tx(txBytes).Hash())))
 	return txBytes, nil
 }
 
 func (c *Client) CalculateGas(txf clientTx.Factory, msgs ...types.Msg) (uint64, error) {
-	cc, err := c.retry(func() (interface{}, error) {
-		_, adjustGas, err := clientTx.CalculateGas(c.Ctx(), txf, msgs...)
-		return adjustGas, err
+	return c.CalculateGasWithContext(context.Background(), txf, msgs...)
+}
+
+func (c *Client) CalculateGasWithContext(ctx context.Context, txf clientTx.Factory, msgs ...types.Msg) (gas uint64, err error) {
+	ctx, span := c.startSpan(ctx, "CalculateGas")
+	defer func() { endSpan(span, err) }()
+
+	cc, err := c.retryWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return simulateGas(ctx, c.Ctx(), txf, msgs...)
 	})
 	if err != nil {
 		return 0, err
@@ -120,3 +154,33 @@ func (c *Client) CalculateGas(txf clientTx.Factory, msgs ...types.Msg) (uint64,
 
 	return cc.(uint64), err
 }
+
+// broadcastTxSync is clientCtx.BroadcastTx in sync mode bounded by ctx, the sdk one uses
+// context.Background.
+func broadcastTxSync(ctx context.Context, clientCtx client.Context, tx []byte) (*types.TxResponse, error) {
+	node, err := clientCtx.GetNode()
+	if err != nil {
+		return nil, err
+	}
+	res, err := node.BroadcastTxSync(ctx, tx)
+	if errRes := client.CheckTendermintError(err, tx); errRes != nil {
+		return errRes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return types.NewResponseFormatBroadcastTx(res), nil
+}
+
+// simulateGas is clientTx.CalculateGas bounded by ctx, it returns the adjusted gas.
+func simulateGas(ctx context.Context, clientCtx client.Context, txf clientTx.Factory, msgs ...types.Msg) (uint64, error) {
+	txBytes, err := txf.BuildSimTx(msgs...)
+	if err != nil {
+		return 0, err
+	}
+	simRes, err := txTypes.NewServiceClient(clientCtx).Simulate(ctx, &txTypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// SingleTransferTo sends amount to toAddr without memo, use SendCoins to get the tx hash.
+func (c *Client) SingleTransferTo(toAddr types.AccAddress, amount types.Coins) error {
+	_, err := c.SendCoins(toAddr, amount, "")
+	return err
+}
+
+// SendCoins signs and broadcasts a MsgSend from the from account and returns the tx hash.
+func (c *Client) SendCoins(toAddr types.AccAddress, amount types.Coins, memo string) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xBankTypes.NewMsgSend(c.Ctx().GetFromAddress(), toAddr, amount)
+	done()
+
+	txBts, err := c.ConstructAndSignTxWithMemo(memo, msg)
+	if err != nil {
+		return "", err
+	}
+	return c.BroadcastTx(txBts)
+}
+
+// MultiSend sends to all outputs in one MsgMultiSend, the single input is the from account
+// with the sum of the outputs.
+func (c *Client) MultiSend(outputs []xBankTypes.Output, memo string) (string, error) {
+	if len(outputs) == 0 {
+		return "", fmt.Errorf("no outputs")
+	}
+	// Add panics on invalid coins, ValidateBasic runs too late to catch them
+	total := types.NewCoins()
+	for i, output := range outputs {
+		if err := output.Coins.Validate(); err != nil {
+			return "", fmt.Errorf("invalid coins of output %d: %w", i, err)
+		}
+		total = total.Add(output.Coins...)
+	}
+
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xBankTypes.NewMsgMultiSend([]xBankTypes.Input{xBankTypes.NewInput(c.Ctx().GetFromAddress(), total)}, outputs)
+	err := msg.ValidateBasic()
+	done()
+	if err != nil {
+		return "", err
+	}
+
+	txBts, err := c.ConstructAndSignTxWithMemo(memo, msg)
+	if err != nil {
+		return "", err
+	}
+	return c.BroadcastTx(txBts)
+}
+
+// ErrTxInclusionPending is returned when a broadcast tx was not found before the wait timed
+// out, it may still be included later.
+var ErrTxInclusionPending = errors.New("tx not included yet")
+
+// WaitTxIncluded polls the tx until it is included in a block or timeout, a tx included
+// with a non zero code is returned together with an error. On timeout the error wraps
+// ErrTxInclusionPending. Every poll is a single request bounded by the timeout, a poll that
+// can't reach its endpoint moves the next one to another endpoint without switching the
+// endpoint used by other calls.
+func (c *Client) WaitTxIncluded(txHash string, timeout time.Duration) (*types.TxResponse, error) {
+	deadline := time.Now().Add(timeout)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	index := c.CurrentEndpointIndex()
+	for {
+		res, err := c.queryTxOnce(ctx, index, txHash)
+		if err == nil {
+			c.metrics.observeGasUsed(res.GasUsed)
+			if res.Code != 0 {
+				return res, fmt.Errorf("tx %s failed with code: %d, codespace: %s, log: %s", txHash, res.Code, res.Codespace, res.RawLog)
+			}
+			return res, nil
+		}
+		if isConnectionError(err) {
+			index = (index + 1) % len(c.rpcClientList)
+		}
+		if ctx.Err() != nil || time.Now().Add(waitTime).After(deadline) {
+			return nil, fmt.Errorf("%w: wait tx %s timeout, last err: %s", ErrTxInclusionPending, txHash, err)
+		}
+		time.Sleep(waitTime)
+	}
+}
+
+// BroadcastTxAndWait broadcasts tx and waits for its inclusion.
+func (c *Client) BroadcastTxAndWait(tx []byte, timeout time.Duration) (*types.TxResponse, error) {
+	txHash, err := c.BroadcastTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	return c.WaitTxIncluded(txHash, timeout)
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func TestSendCoins(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	to := types.AccAddress([]byte("recipient___________"))
+	amount := types.NewCoins(types.NewInt64Coin("untrn", 100))
+
+	if _, err := c.SendCoins(to, amount, "memo"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := broadcastMsgs(t, c, chain)
+	send, ok := msgs[0].(*xBankTypes.MsgSend)
+	if !ok {
+		t.Fatalf("broadcast %T", msgs[0])
+	}
+	done := core.UseSdkConfigContext("neutron")
+	toStr := to.String()
+	done()
+	if send.ToAddress != toStr || !send.Amount.IsEqual(amount) {
+		t.Fatalf("unexpected msg %v", send)
+	}
+}
+
+func TestMultiSend(t *testing.T) {
+	c, chain := newMockChainClient(t)
+
+	done := core.UseSdkConfigContext("neutron")
+	outputs := []xBankTypes.Output{
+		xBankTypes.NewOutput(types.AccAddress([]byte("recipient_a_________")), types.NewCoins(types.NewInt64Coin("untrn", 100))),
+		xBankTypes.NewOutput(types.AccAddress([]byte("recipient_b_________")), types.NewCoins(types.NewInt64Coin("untrn", 50))),
+	}
+	invalid := []xBankTypes.Output{
+		outputs[0],
+		{Address: outputs[1].Address, Coins: types.Coins{{Denom: "untrn", Amount: types.NewInt(-1)}}},
+	}
+	done()
+
+	if _, err := c.MultiSend(invalid, ""); err == nil {
+		t.Fatal("expected invalid coins error")
+	}
+	if len(chain.BroadcastTxs()) != 0 {
+		t.Fatal("invalid multi send was broadcast")
+	}
+
+	if _, err := c.MultiSend(outputs, ""); err != nil {
+		t.Fatal(err)
+	}
+	msgs := broadcastMsgs(t, c, chain)
+	multiSend, ok := msgs[0].(*xBankTypes.MsgMultiSend)
+	if !ok {
+		t.Fatalf("broadcast %T", msgs[0])
+	}
+	if len(multiSend.Inputs) != 1 || !multiSend.Inputs[0].Coins.IsEqual(types.NewCoins(types.NewInt64Coin("untrn", 150))) {
+		t.Fatalf("unexpected inputs %v", multiSend.Inputs)
+	}
+}
+
+// broadcastMsgs decodes the msgs of the single tx broadcast to chain.
+func broadcastMsgs(t *testing.T, c *Client, chain *clienttest.Chain) []types.Msg {
+	txs := chain.BroadcastTxs()
+	if len(txs) != 1 {
+		t.Fatalf("broadcast %d txs", len(txs))
+	}
+	tx, err := c.GetTxConfig().TxDecoder()(txs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx.GetMsgs()
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	xAuthzTypes "github.com/cosmos/cosmos-sdk/x/authz"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	interchainqueriesTypes "github.com/neutron-org/neutron/v2/x/interchainqueries/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+// DecodedTx is a TxResponse with its body unpacked into concrete msgs.
+type DecodedTx struct {
+	TxHash   string
+	Height   int64
+	Success  bool
+	Code     uint32
+	Signers  []string
+	Fee      types.Coins
+	GasLimit uint64
+	Memo     string
+	Msgs     []DecodedMsg
+	Response *types.TxResponse
+}
+
+// DecodedMsg is a single tx msg. For MsgExecuteContract the contract msg is split into its
+// top level key (ExecuteAction) and value (ExecuteMsg), a msg that is not a single key
+// object has no ExecuteAction and is kept whole in ExecuteMsg; for authz MsgExec the wrapped msgs
+// are decoded into Inner.
+type DecodedMsg struct {
+	Index         int
+	TypeUrl       string
+	Msg           types.Msg
+	ExecuteAction string
+	ExecuteMsg    json.RawMessage
+	Inner         []DecodedMsg
+}
+
+func (m DecodedMsg) AsExecuteContract() (*xWasmTypes.MsgExecuteContract, bool) {
+	msg, ok := m.Msg.(*xWasmTypes.MsgExecuteContract)
+	return msg, ok
+}
+
+func (m DecodedMsg) AsSend() (*xBankTypes.MsgSend, bool) {
+	msg, ok := m.Msg.(*xBankTypes.MsgSend)
+	return msg, ok
+}
+
+func (m DecodedMsg) AsTransfer() (*ibcTransferTypes.MsgTransfer, bool) {
+	msg, ok := m.Msg.(*ibcTransferTypes.MsgTransfer)
+	return msg, ok
+}
+
+func (m DecodedMsg) AsSubmitQueryResult() (*interchainqueriesTypes.MsgSubmitQueryResult, bool) {
+	msg, ok := m.Msg.(*interchainqueriesTypes.MsgSubmitQueryResult)
+	return msg, ok
+}
+
+func (m DecodedMsg) AsExec() (*xAuthzTypes.MsgExec, bool) {
+	msg, ok := m.Msg.(*xAuthzTypes.MsgExec)
+	return msg, ok
+}
+
+// UnmarshalExecuteMsg json decodes the value under ExecuteAction into v.
+func (m DecodedMsg) UnmarshalExecuteMsg(v interface{}) error {
+	if _, ok := m.AsExecuteContract(); !ok {
+		return fmt.Errorf("msg %s is not MsgExecuteContract", m.TypeUrl)
+	}
+	return json.Unmarshal(m.ExecuteMsg, v)
+}
+
+// Flatten returns the msgs with authz MsgExec replaced by its inner msgs, recursively.
+func (t *DecodedTx) Flatten() []DecodedMsg {
+	return flattenMsgs(t.Msgs)
+}
+
+func flattenMsgs(msgs []DecodedMsg) []DecodedMsg {
+	ret := make([]DecodedMsg, 0, len(msgs))
+	for _, msg := range msgs {
+		if len(msg.Inner) > 0 {
+			ret = append(ret, flattenMsgs(msg.Inner)...)
+			continue
+		}
+		ret = append(ret, msg)
+	}
+	return ret
+}
+
+func (c *Client) DecodeTx(txResponse *types.TxResponse) (*DecodedTx, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	return c.decodeTx(txResponse)
+}
+
+// GetBlockDecodedTxs returns the txs of height decoded, txs failed to be parsed are skipped.
+func (c *Client) GetBlockDecodedTxs(height int64) ([]*DecodedTx, error) {
+	txs, err := c.GetBlockTxsWithParseErrSkip(height)
+	if err != nil {
+		return nil, err
+	}
+
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	decodedTxs := make([]*DecodedTx, 0, len(txs))
+	for _, txResponse := range txs {
+		decodedTx, err := c.decodeTx(txResponse)
+		if err != nil {
+			return nil, fmt.Errorf("decode tx %s err: %s", txResponse.TxHash, err)
+		}
+		decodedTxs = append(decodedTxs, decodedTx)
+	}
+	return decodedTxs, nil
+}
+
+func (c *Client) decodeTx(txResponse *types.TxResponse) (*DecodedTx, error) {
+	tx, err := c.unpackTx(txResponse.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := txSigners(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := decodeMsgs(tx.GetMsgs())
+	if err != nil {
+		return nil, err
+	}
+
+	decodedTx := &DecodedTx{
+		TxHash:   txResponse.TxHash,
+		Height:   txResponse.Height,
+		Success:  txResponse.Code == 0,
+		Code:     txResponse.Code,
+		Signers:  signers,
+		Msgs:     msgs,
+		Response: txResponse,
+	}
+	if tx.AuthInfo != nil && tx.AuthInfo.Fee != nil {
+		decodedTx.Fee = tx.AuthInfo.Fee.Amount
+		decodedTx.GasLimit = tx.AuthInfo.Fee.GasLimit
+	}
+	if tx.Body != nil {
+		decodedTx.Memo = tx.Body.Memo
+	}
+	return decodedTx, nil
+}
+
+// unpackTx uses the cached value when the Any was built locally and unmarshals it otherwise.
+func (c *Client) unpackTx(txAny *codecTypes.Any) (*txTypes.Tx, error) {
+	if txAny == nil {
+		return nil, fmt.Errorf("tx is nil")
+	}
+	if tx, ok := txAny.GetCachedValue().(*txTypes.Tx); ok {
+		return tx, nil
+	}
+
+	tx := new(txTypes.Tx)
+	if err := c.clientCtx.Codec.Unmarshal(txAny.Value, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.UnpackInterfaces(c.clientCtx.InterfaceRegistry); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// txSigners recovers from the panic GetSigners raises on addresses with another bech32 prefix.
+func txSigners(tx *txTypes.Tx) (signers []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("get signers err: %v", r)
+		}
+	}()
+
+	for _, signer := range tx.GetSigners() {
+		signers = append(signers, signer.String())
+	}
+	return signers, nil
+}
+
+func decodeMsgs(msgs []types.Msg) ([]DecodedMsg, error) {
+	decodedMsgs := make([]DecodedMsg, 0, len(msgs))
+	for i, msg := range msgs {
+		decodedMsg := DecodedMsg{
+			Index:   i,
+			TypeUrl: types.MsgTypeURL(msg),
+			Msg:     msg,
+		}
+
+		switch m := msg.(type) {
+		case *xWasmTypes.MsgExecuteContract:
+			action, executeMsg := splitExecuteMsg(m.Msg)
+			decodedMsg.ExecuteAction = action
+			decodedMsg.ExecuteMsg = executeMsg
+		case *xAuthzTypes.MsgExec:
+			innerMsgs, err := m.GetMessages()
+			if err != nil {
+				return nil, err
+			}
+			inner, err := decodeMsgs(innerMsgs)
+			if err != nil {
+				return nil, err
+			}
+			decodedMsg.Inner = inner
+		}
+		decodedMsgs = append(decodedMsgs, decodedMsg)
+	}
+	return decodedMsgs, nil
+}
+
+// splitExecuteMsg splits a cosmwasm execute msg like {"era_update":{...}} into its key and value.
+// Msgs of another shape, like a bare "claim" string, have no action and are kept whole.
+func splitExecuteMsg(msg []byte) (string, json.RawMessage) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(msg, &fields); err != nil || len(fields) != 1 {
+		return "", json.RawMessage(msg)
+	}
+	for action, value := range fields {
+		return action, value
+	}
+	return "", json.RawMessage(msg)
+}
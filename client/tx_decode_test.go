@@ -0,0 +1,95 @@
+package client
+
+import (
+	"testing"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	xAuthzTypes "github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func TestDecodeTx(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	c := &Client{
+		accountPrefix: "neutron",
+		clientCtx: client.Context{}.
+			WithCodec(encodingConfig.Marshaler).
+			WithInterfaceRegistry(encodingConfig.InterfaceRegistry).
+			WithTxConfig(encodingConfig.TxConfig),
+	}
+
+	granter := types.AccAddress([]byte("granter_____________"))
+	grantee := types.AccAddress([]byte("grantee_____________"))
+
+	done := core.UseSdkConfigContext("neutron")
+	execute := &xWasmTypes.MsgExecuteContract{
+		Sender:   granter.String(),
+		Contract: "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq",
+		Msg:      []byte(`{"era_update":{"pool_addr":"neutron1pool"}}`),
+	}
+	exec := xAuthzTypes.NewMsgExec(grantee, []types.Msg{execute})
+	done()
+
+	txBuilder := encodingConfig.TxConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(&exec); err != nil {
+		t.Fatal(err)
+	}
+	txBuilder.SetMemo("era update")
+	txBuilder.SetGasLimit(200000)
+	txBuilder.SetFeeAmount(types.NewCoins(types.NewInt64Coin("untrn", 1000)))
+	txBytes, err := encodingConfig.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decodedTx, err := c.DecodeTx(&types.TxResponse{
+		TxHash: "HASH",
+		Height: 10,
+		Tx:     &codecTypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: txBytes},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decodedTx.Success || decodedTx.Memo != "era update" || decodedTx.GasLimit != 200000 {
+		t.Fatalf("unexpected decoded tx: %+v", decodedTx)
+	}
+	if len(decodedTx.Signers) != 1 || decodedTx.Signers[0] != grantee.String() {
+		t.Fatalf("unexpected signers: %v", decodedTx.Signers)
+	}
+
+	msgs := decodedTx.Flatten()
+	if len(msgs) != 1 {
+		t.Fatalf("got %d flattened msgs, want 1", len(msgs))
+	}
+	if msgs[0].ExecuteAction != "era_update" {
+		t.Fatalf("got action %s, want era_update", msgs[0].ExecuteAction)
+	}
+	var eraUpdate struct {
+		PoolAddr string `json:"pool_addr"`
+	}
+	if err := msgs[0].UnmarshalExecuteMsg(&eraUpdate); err != nil {
+		t.Fatal(err)
+	}
+	if eraUpdate.PoolAddr != "neutron1pool" {
+		t.Fatalf("got pool addr %s", eraUpdate.PoolAddr)
+	}
+}
+
+func TestSplitExecuteMsg(t *testing.T) {
+	for _, tc := range []struct {
+		msg, action, value string
+	}{
+		{`{"era_update":{"pool_addr":"neutron1pool"}}`, "era_update", `{"pool_addr":"neutron1pool"}`},
+		{`"claim"`, "", `"claim"`},
+		{`[1,2]`, "", `[1,2]`},
+		{`{"a":1,"b":2}`, "", `{"a":1,"b":2}`},
+	} {
+		action, value := splitExecuteMsg([]byte(tc.msg))
+		if action != tc.action || string(value) != tc.value {
+			t.Errorf("split %s got %q %s", tc.msg, action, value)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tmTypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	authSigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+)
+
+const (
+	txJournalCursor           = "tx_journal"
+	defaultTxJournalRetention = 7 * 24 * time.Hour
+)
+
+var (
+	ErrTxAlreadyCompleted = errors.New("tx of idempotency key already completed")
+	ErrTxPending          = errors.New("tx of idempotency key is pending")
+)
+
+type TxJournalStatus string
+
+const (
+	TxJournalPending TxJournalStatus = "pending"
+	// TxJournalCommitted means the tx was included with code 0
+	TxJournalCommitted TxJournalStatus = "committed"
+	// TxJournalFailed means the tx was rejected by CheckTx or included with a non zero code
+	TxJournalFailed TxJournalStatus = "failed"
+	// TxJournalExpired means the tx was never included and its sequence was used by another tx
+	TxJournalExpired TxJournalStatus = "expired"
+)
+
+// TxJournalClient is what a TxJournal needs to sign, broadcast and look up its txs.
+type TxJournalClient interface {
+	TxSender
+	Querier
+	QueryTxAfterSequence(txHash string) (uint64, *types.TxResponse, error)
+}
+
+type TxJournalEntry struct {
+	Key       string          `json:"key"`
+	TxHash    string          `json:"tx_hash"`
+	TxBytes   []byte          `json:"tx_bytes"`
+	Sequence  uint64          `json:"sequence"`
+	Status    TxJournalStatus `json:"status"`
+	Height    int64           `json:"height,omitempty"`
+	Code      uint32          `json:"code,omitempty"`
+	Log       string          `json:"log,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TxJournal records every signed tx under a caller supplied idempotency key before it is
+// broadcast, so after a crash Reconcile can find out whether it was included instead of
+// sending it a second time. Finished entries are kept for the retention and then dropped.
+type TxJournal struct {
+	client    TxJournalClient
+	store     utils.CursorStorer
+	retention time.Duration
+	logger    log.Logger
+
+	mutex   sync.Mutex
+	entries map[string]*TxJournalEntry
+}
+
+func NewTxJournal(c TxJournalClient, store utils.CursorStorer, retention time.Duration, logger log.Logger) (*TxJournal, error) {
+	if isNil(c) {
+		return nil, fmt.Errorf("client is nil")
+	}
+	if isNil(store) {
+		return nil, fmt.Errorf("journal store is nil")
+	}
+	if isNil(logger) {
+		return nil, fmt.Errorf("logger is nil")
+	}
+	if retention <= 0 {
+		retention = defaultTxJournalRetention
+	}
+
+	entries := make(map[string]*TxJournalEntry)
+	value, found, err := store.GetCursor(txJournalCursor)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if err := json.Unmarshal([]byte(value), &entries); err != nil {
+			return nil, fmt.Errorf("unmarshal tx journal err: %w", err)
+		}
+	}
+	return &TxJournal{
+		client:    c,
+		store:     store,
+		retention: retention,
+		logger:    logger,
+		entries:   entries,
+	}, nil
+}
+
+// Send signs msgs, journals the tx under key and broadcasts it. It returns
+// ErrTxAlreadyCompleted if key was committed before and ErrTxPending if a tx of key is
+// still unknown, in both cases together with the journaled tx hash.
+func (j *TxJournal) Send(key, memo string, msgs ...types.Msg) (string, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if entry, ok := j.entries[key]; ok {
+		switch entry.Status {
+		case TxJournalCommitted:
+			return entry.TxHash, ErrTxAlreadyCompleted
+		case TxJournalPending:
+			return entry.TxHash, ErrTxPending
+		}
+	}
+
+	txBts, err := j.client.ConstructAndSignTxWithMemo(memo, msgs...)
+	if err != nil {
+		return "", err
+	}
+	sequence, err := txSequence(j.client.GetTxConfig(), txBts)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	entry := &TxJournalEntry{
+		Key:       key,
+		TxHash:    fmt.Sprintf("%X", tmTypes.Tx(txBts).Hash()),
+		TxBytes:   txBts,
+		Sequence:  sequence,
+		Status:    TxJournalPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	j.entries[key] = entry
+	if err := j.persist(); err != nil {
+		delete(j.entries, key)
+		return "", fmt.Errorf("persist tx journal err: %w", err)
+	}
+
+	return entry.TxHash, j.broadcast(entry)
+}
+
+// broadcast marks entry failed when CheckTx rejected it, on other errors it stays pending.
+func (j *TxJournal) broadcast(entry *TxJournalEntry) error {
+	txRes, err := j.client.BroadcastTxResponse(context.Background(), entry.TxBytes)
+	if err != nil {
+		return fmt.Errorf("broadcast tx %s err: %w", entry.TxHash, err)
+	}
+	if txRes.Code == 0 {
+		return nil
+	}
+	// a tx already in the mempool of the node is not a failure
+	if strings.Contains(txRes.RawLog, "tx already exists in cache") {
+		return nil
+	}
+	j.finish(entry, TxJournalFailed, 0, txRes.Code, txRes.RawLog)
+	if err := j.persist(); err != nil {
+		j.logger.Warn("persist tx journal failed", "err", err)
+	}
+	return fmt.Errorf("broadcast err with res.code: %d, res.Codespace: %s", txRes.Code, txRes.Codespace)
+}
+
+// Reconcile resolves every pending entry: included txs are marked committed or failed,
+// txs whose sequence was used by another tx are expired and the others are broadcast again.
+// It should be called on startup before sending new txs.
+func (j *TxJournal) Reconcile() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	pending := make([]*TxJournalEntry, 0)
+	for _, entry := range j.entries {
+		if entry.Status == TxJournalPending {
+			pending = append(pending, entry)
+		}
+	}
+	// a tx is only valid once the txs of lower sequences are included
+	sort.Slice(pending, func(a, b int) bool { return pending[a].Sequence < pending[b].Sequence })
+
+	var firstErr error
+	for _, entry := range pending {
+		res, err := j.client.QueryTxByHash(entry.TxHash)
+		if err == nil {
+			j.included(entry, res)
+			continue
+		}
+		if !isTxNotFoundError(err, entry.TxHash) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("query tx %s err: %w", entry.TxHash, err)
+			}
+			continue
+		}
+
+		// the not found answer may come from an endpoint behind the one serving the
+		// sequence, look the tx up again where the sequence was read
+		sequence, res, err := j.client.QueryTxAfterSequence(entry.TxHash)
+		if err != nil {
+			return err
+		}
+		if res != nil {
+			j.included(entry, res)
+			continue
+		}
+		if sequence > entry.Sequence {
+			j.finish(entry, TxJournalExpired, 0, 0, "")
+			j.logger.Warn("tx journal entry expired", "key", entry.Key, "tx hash", entry.TxHash, "sequence", entry.Sequence)
+			continue
+		}
+		j.logger.Info("rebroadcast tx journal entry", "key", entry.Key, "tx hash", entry.TxHash)
+		if err := j.broadcast(entry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	j.prune()
+	if err := j.persist(); err != nil {
+		return fmt.Errorf("persist tx journal err: %w", err)
+	}
+	return firstErr
+}
+
+// included marks entry committed or failed by the result of its included tx.
+func (j *TxJournal) included(entry *TxJournalEntry, res *types.TxResponse) {
+	status := TxJournalCommitted
+	if res.Code != 0 {
+		status = TxJournalFailed
+	}
+	j.finish(entry, status, res.Height, res.Code, res.RawLog)
+	j.logger.Info("tx journal entry reconciled", "key", entry.Key, "tx hash", entry.TxHash, "status", status)
+}
+
+// IsCompleted reports whether a tx of key was committed, with its journal entry.
+func (j *TxJournal) IsCompleted(key string) (bool, *TxJournalEntry) {
+	entry := j.Entry(key)
+	return entry != nil && entry.Status == TxJournalCommitted, entry
+}
+
+// Entry returns a copy of the entry of key, nil if not journaled.
+func (j *TxJournal) Entry(key string) *TxJournalEntry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry, ok := j.entries[key]
+	if !ok {
+		return nil
+	}
+	copied := *entry
+	return &copied
+}
+
+// Pending returns copies of all pending entries.
+func (j *TxJournal) Pending() []TxJournalEntry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	pending := make([]TxJournalEntry, 0)
+	for _, entry := range j.entries {
+		if entry.Status == TxJournalPending {
+			pending = append(pending, *entry)
+		}
+	}
+	return pending
+}
+
+func (j *TxJournal) finish(entry *TxJournalEntry, status TxJournalStatus, height int64, code uint32, rawLog string) {
+	entry.Status = status
+	entry.Height = height
+	entry.Code = code
+	entry.Log = rawLog
+	entry.UpdatedAt = time.Now().UTC()
+	// the signed bytes are only needed to rebroadcast
+	entry.TxBytes = nil
+}
+
+func (j *TxJournal) prune() {
+	deadline := time.Now().Add(-j.retention)
+	for key, entry := range j.entries {
+		if entry.Status != TxJournalPending && entry.UpdatedAt.Before(deadline) {
+			delete(j.entries, key)
+		}
+	}
+}
+
+func (j *TxJournal) persist() error {
+	data, err := json.Marshal(j.entries)
+	if err != nil {
+		return err
+	}
+	return j.store.PutCursor(txJournalCursor, string(data))
+}
+
+// txSequence returns the sequence of the first signer of a signed tx.
+func txSequence(txConfig client.TxConfig, txBts []byte) (uint64, error) {
+	tx, err := txConfig.TxDecoder()(txBts)
+	if err != nil {
+		return 0, err
+	}
+	sigTx, ok := tx.(authSigning.SigVerifiableTx)
+	if !ok {
+		return 0, fmt.Errorf("tx is not a signed tx")
+	}
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return 0, err
+	}
+	if len(sigs) == 0 {
+		return 0, fmt.Errorf("tx has no signatures")
+	}
+	return sigs[0].Sequence, nil
+}
+
+// isTxNotFoundError matches the err of the rpc tx route for an unknown hash, other errs
+// don't prove the tx was not included.
+func isTxNotFoundError(err error, txHash string) bool {
+	return strings.Contains(err.Error(), fmt.Sprintf("tx (%s) not found", strings.ToUpper(txHash)))
+}
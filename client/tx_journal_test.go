@@ -0,0 +1,206 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	xAuthTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+	"github.com/stafihub/neutron-relay-sdk/common/utils"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+func TestTxJournalReload(t *testing.T) {
+	store, err := utils.NewBlockstore(t.TempDir(), 0, "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewLog("client", "journal")
+	j, err := NewTxJournal(&Client{}, store, time.Hour, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	j.entries["era-1"] = &TxJournalEntry{Key: "era-1", TxHash: "AA", Status: TxJournalCommitted, UpdatedAt: now}
+	j.entries["era-0"] = &TxJournalEntry{Key: "era-0", TxHash: "BB", Status: TxJournalCommitted, UpdatedAt: now.Add(-2 * time.Hour)}
+	j.entries["era-2"] = &TxJournalEntry{Key: "era-2", TxHash: "CC", TxBytes: []byte{1}, Sequence: 3, Status: TxJournalPending, UpdatedAt: now.Add(-2 * time.Hour)}
+	j.prune()
+	if err := j.persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = NewTxJournal(&Client{}, store, time.Hour, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Entry("era-0") != nil {
+		t.Fatal("expired finished entry not pruned")
+	}
+	if completed, entry := j.IsCompleted("era-1"); !completed || entry.TxHash != "AA" {
+		t.Fatalf("unexpected era-1 entry %+v", entry)
+	}
+	if pending := j.Pending(); len(pending) != 1 || pending[0].Sequence != 3 {
+		t.Fatalf("unexpected pending %+v", pending)
+	}
+
+	if txHash, err := j.Send("era-1", ""); !errors.Is(err, ErrTxAlreadyCompleted) || txHash != "AA" {
+		t.Fatalf("unexpected send result %s %v", txHash, err)
+	}
+	if txHash, err := j.Send("era-2", ""); !errors.Is(err, ErrTxPending) || txHash != "CC" {
+		t.Fatalf("unexpected send result %s %v", txHash, err)
+	}
+}
+
+func TestTxJournalReconcile(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	store, err := utils.NewBlockstore(t.TempDir(), 0, "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewTxJournal(c, store, time.Hour, log.NewLog("client", "journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq"
+	send := func(key string) {
+		done := core.UseSdkConfigContext("neutron")
+		msg := &xWasmTypes.MsgExecuteContract{
+			Sender:   c.GetFromAddress().String(),
+			Contract: contract,
+			Msg:      []byte(`{"` + key + `":{}}`),
+		}
+		done()
+		if _, err := j.Send(key, "", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// included with code 0
+	send("committed")
+	// included with a non zero code
+	chain.SetDeliverTxHandler(func([]byte) abci.ResponseDeliverTx {
+		return abci.ResponseDeliverTx{Code: 5, Log: "insufficient funds"}
+	})
+	send("failed")
+	// accepted but lost before inclusion, the account sequence is still its sequence
+	chain.SetAutoCommit(false)
+	send("rebroadcast")
+	// never included and its sequence was used by another tx
+	j.entries["expired"] = &TxJournalEntry{Key: "expired", TxHash: strings.Repeat("AB", 32), TxBytes: []byte{1}, Sequence: 2, Status: TxJournalPending}
+
+	chain.SetAutoCommit(true)
+	chain.SetDeliverTxHandler(func([]byte) abci.ResponseDeliverTx { return abci.ResponseDeliverTx{} })
+	if err := j.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, status := range map[string]TxJournalStatus{
+		"committed":   TxJournalCommitted,
+		"failed":      TxJournalFailed,
+		"expired":     TxJournalExpired,
+		"rebroadcast": TxJournalPending,
+	} {
+		if entry := j.Entry(key); entry.Status != status {
+			t.Errorf("%s entry is %s, want %s", key, entry.Status, status)
+		}
+	}
+	if entry := j.Entry("failed"); entry.Code != 5 || entry.Height == 0 {
+		t.Fatalf("unexpected failed entry %+v", entry)
+	}
+	txs := chain.BroadcastTxs()
+	if len(txs) != 4 || !bytes.Equal(txs[2], txs[3]) {
+		t.Fatalf("pending tx was not rebroadcast, %d broadcasts", len(txs))
+	}
+
+	if err := j.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if entry := j.Entry("rebroadcast"); entry.Status != TxJournalCommitted {
+		t.Fatalf("rebroadcast tx is %s after inclusion", entry.Status)
+	}
+}
+
+func TestIsTxNotFoundError(t *testing.T) {
+	hash := strings.Repeat("AB", 32)
+	if !isTxNotFoundError(errors.New("RPC error -32603 - Internal error: tx ("+hash+") not found"), strings.ToLower(hash)) {
+		t.Fatal("tx not found err not matched")
+	}
+	for _, err := range []error{
+		errors.New("rpc error: code = NotFound desc = account not found"),
+		errors.New("RPC error -32603 - Internal error: tx (" + strings.Repeat("CD", 32) + ") not found"),
+		errors.New("method not found"),
+	} {
+		if isTxNotFoundError(err, hash) {
+			t.Errorf("%s matched", err)
+		}
+	}
+}
+
+func TestNewTxJournalTypedNil(t *testing.T) {
+	store, err := utils.NewBlockstore(t.TempDir(), 0, "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewLog("client", "journal")
+	if _, err := NewTxJournal((*Client)(nil), store, time.Hour, logger); err == nil {
+		t.Fatal("nil client accepted")
+	}
+	if _, err := NewTxJournal(&Client{}, (*utils.Blockstore)(nil), time.Hour, logger); err == nil {
+		t.Fatal("nil store accepted")
+	}
+}
+
+func TestTxJournalReconcileIncludedAfterNotFound(t *testing.T) {
+	c, chain := newMockChainClient(t)
+	store, err := utils.NewBlockstore(t.TempDir(), 0, "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewTxJournal(c, store, time.Hour, log.NewLog("client", "journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, account := newTestAccount(t)
+	done := core.UseSdkConfigContext("neutron")
+	msg := &xWasmTypes.MsgExecuteContract{
+		Sender:   c.GetFromAddress().String(),
+		Contract: "neutron14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s5c2epq",
+		Msg:      []byte(`{"era_update":{}}`),
+	}
+	done()
+	chain.SetAutoCommit(false)
+	if _, err := j.Send("late", "", msg); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tx is included and the sequence advances after the first lookup missed it
+	included := false
+	chain.HandleQuery(clienttest.AccountQueryPath, func([]byte, int64) ([]byte, error) {
+		if !included {
+			included = true
+			chain.CommitBlock(clienttest.Tx{Bytes: chain.BroadcastTxs()[0]})
+			account.Sequence++
+		}
+		any, err := codecTypes.NewAnyWithValue(account)
+		if err != nil {
+			return nil, err
+		}
+		return (&xAuthTypes.QueryAccountResponse{Account: any}).Marshal()
+	})
+	if err := j.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if entry := j.Entry("late"); entry.Status != TxJournalCommitted || entry.Height != chain.Height() {
+		t.Fatalf("late included tx is %+v", entry)
+	}
+	if len(chain.BroadcastTxs()) != 1 {
+		t.Fatalf("included tx was sent %d times", len(chain.BroadcastTxs()))
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	xDistriTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	xStakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+const defaultWaitTxTimeout = time.Minute
+
+// StakingTxResult is parsed from the events of an included staking or distribution tx.
+type StakingTxResult struct {
+	TxHash string
+	Height int64
+	// Amount is the delegated, unbonded, redelegated or withdrawn amount
+	Amount types.Coins
+	// CompletionTime is set for undelegate and redelegate
+	CompletionTime  time.Time
+	WithdrawAddress string
+	Response        *types.TxResponse
+}
+
+func (c *Client) Delegate(valAddr types.ValAddress, amount types.Coin, memo string) (*StakingTxResult, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xStakeTypes.NewMsgDelegate(c.Ctx().GetFromAddress(), valAddr, amount)
+	done()
+
+	return c.sendStakingTx(xStakeTypes.EventTypeDelegate, memo, msg)
+}
+
+func (c *Client) Undelegate(valAddr types.ValAddress, amount types.Coin, memo string) (*StakingTxResult, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xStakeTypes.NewMsgUndelegate(c.Ctx().GetFromAddress(), valAddr, amount)
+	done()
+
+	return c.sendStakingTx(xStakeTypes.EventTypeUnbond, memo, msg)
+}
+
+func (c *Client) BeginRedelegate(srcValAddr, dstValAddr types.ValAddress, amount types.Coin, memo string) (*StakingTxResult, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xStakeTypes.NewMsgBeginRedelegate(c.Ctx().GetFromAddress(), srcValAddr, dstValAddr, amount)
+	done()
+
+	return c.sendStakingTx(xStakeTypes.EventTypeRedelegate, memo, msg)
+}
+
+// WithdrawDelegatorReward withdraws rewards of every given validator in one tx, Amount is the total.
+func (c *Client) WithdrawDelegatorReward(valAddrs []types.ValAddress, memo string) (*StakingTxResult, error) {
+	if len(valAddrs) == 0 {
+		return nil, fmt.Errorf("no validators")
+	}
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msgs := make([]types.Msg, len(valAddrs))
+	for i, valAddr := range valAddrs {
+		msgs[i] = xDistriTypes.NewMsgWithdrawDelegatorReward(c.Ctx().GetFromAddress(), valAddr)
+	}
+	done()
+
+	return c.sendStakingTx(xDistriTypes.EventTypeWithdrawRewards, memo, msgs...)
+}
+
+func (c *Client) SetWithdrawAddress(withdrawAddr types.AccAddress, memo string) (*StakingTxResult, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	msg := xDistriTypes.NewMsgSetWithdrawAddress(c.Ctx().GetFromAddress(), withdrawAddr)
+	done()
+
+	return c.sendStakingTx(xDistriTypes.EventTypeSetWithdrawAddress, memo, msg)
+}
+
+// sendStakingTx broadcasts msgs and waits for inclusion. Once the tx has a hash the result
+// holds it along with any error: the tx was rejected by CheckTx, may still be included if
+// the error wraps ErrTxInclusionPending, was included with a non zero code or was included
+// without the expected event.
+func (c *Client) sendStakingTx(eventType, memo string, msgs ...types.Msg) (*StakingTxResult, error) {
+	txBts, err := c.ConstructAndSignTxWithMemo(memo, msgs...)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := c.BroadcastTx(txBts)
+	if err != nil {
+		if len(txHash) == 0 {
+			return nil, err
+		}
+		return &StakingTxResult{TxHash: txHash}, err
+	}
+	timeout := c.txWaitTimeout
+	if timeout <= 0 {
+		timeout = defaultWaitTxTimeout
+	}
+	res, err := c.WaitTxIncluded(txHash, timeout)
+	if err != nil {
+		ret := &StakingTxResult{TxHash: txHash, Response: res}
+		if res != nil {
+			ret.Height = res.Height
+		}
+		return ret, err
+	}
+	return parseStakingTxResult(eventType, res)
+}
+
+// parseStakingTxResult returns the result with the hash and height of res also when the
+// events can't be parsed.
+func parseStakingTxResult(eventType string, res *types.TxResponse) (*StakingTxResult, error) {
+	ret := &StakingTxResult{
+		TxHash:   res.TxHash,
+		Height:   res.Height,
+		Amount:   types.NewCoins(),
+		Response: res,
+	}
+
+	found := false
+	for _, event := range res.Events {
+		if event.Type != eventType {
+			continue
+		}
+		found = true
+		if err := ret.addEvent(event); err != nil {
+			return ret, fmt.Errorf("parse %s event of tx %s err: %s", eventType, res.TxHash, err)
+		}
+	}
+	if !found {
+		return ret, fmt.Errorf("event %s not found in tx %s", eventType, res.TxHash)
+	}
+	return ret, nil
+}
+
+func (r *StakingTxResult) addEvent(event abci.Event) error {
+	for _, attr := range event.Attributes {
+		switch attr.Key {
+		case types.AttributeKeyAmount:
+			// withdraw_rewards emits an empty amount when there is nothing to withdraw
+			if len(attr.Value) == 0 {
+				continue
+			}
+			amount, err := types.ParseCoinsNormalized(attr.Value)
+			if err != nil {
+				return err
+			}
+			r.Amount = r.Amount.Add(amount...)
+		case xStakeTypes.AttributeKeyCompletionTime:
+			completionTime, err := time.Parse(time.RFC3339, attr.Value)
+			if err != nil {
+				return err
+			}
+			r.CompletionTime = completionTime
+		case xDistriTypes.AttributeKeyWithdrawAddress:
+			r.WithdrawAddress = attr.Value
+		}
+	}
+	return nil
+}
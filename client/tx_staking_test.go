@@ -0,0 +1,96 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmTypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/neutron-relay-sdk/client/clienttest"
+	"github.com/stafihub/neutron-relay-sdk/common/log"
+)
+
+func TestParseStakingTxResult(t *testing.T) {
+	res := &types.TxResponse{
+		TxHash: "HASH",
+		Height: 100,
+		Events: []abci.Event{
+			{Type: "withdraw_rewards", Attributes: []abci.EventAttribute{{Key: "amount", Value: "12uatom"}}},
+			{Type: "unbond", Attributes: []abci.EventAttribute{
+				{Key: "validator", Value: "cosmosvaloper1val"},
+				{Key: "amount", Value: "1000uatom"},
+				{Key: "completion_time", Value: "2024-01-02T03:04:05Z"},
+			}},
+		},
+	}
+
+	result, err := parseStakingTxResult("unbond", res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Amount.IsEqual(types.NewCoins(types.NewInt64Coin("uatom", 1000))) {
+		t.Fatalf("got amount %s", result.Amount)
+	}
+	if !result.CompletionTime.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("got completion time %s", result.CompletionTime)
+	}
+
+	result, err = parseStakingTxResult("redelegate", res)
+	if err == nil {
+		t.Fatal("expect err when event is missing")
+	}
+	if result == nil || result.TxHash != "HASH" || result.Height != 100 {
+		t.Fatalf("tx hash dropped with missing event: %+v", result)
+	}
+}
+
+func TestSendStakingTxPending(t *testing.T) {
+	chain := clienttest.NewChain("neutron-test-1")
+	defer chain.Close()
+	kr, account := newTestAccount(t)
+	chain.SetAccount(account)
+	// shorter than waitTime, so a pending tx is reported after the first lookup, which
+	// still needs time to finish under the race detector
+	c, err := NewClient(kr, "relayer", "0.005untrn", "neutron", []string{chain.URL()}, log.NewLog("client", "test"),
+		WithTxWaitTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valAddr := types.ValAddress([]byte("validator___________"))
+	amount := types.NewInt64Coin("untrn", 100)
+
+	chain.SetAutoCommit(false)
+	result, err := c.Delegate(valAddr, amount, "")
+	if !errors.Is(err, ErrTxInclusionPending) {
+		t.Fatalf("got err %v, want pending", err)
+	}
+	txs := chain.BroadcastTxs()
+	if result == nil || len(txs) != 1 || result.TxHash != fmt.Sprintf("%X", tmTypes.Tx(txs[0]).Hash()) {
+		t.Fatalf("unexpected pending result %+v", result)
+	}
+
+	chain.SetAutoCommit(true)
+	chain.SetDeliverTxHandler(func([]byte) abci.ResponseDeliverTx {
+		return abci.ResponseDeliverTx{Code: 5, Log: "insufficient funds"}
+	})
+	result, err = c.Delegate(valAddr, amount, "")
+	if err == nil || errors.Is(err, ErrTxInclusionPending) {
+		t.Fatalf("got err %v, want a failed tx", err)
+	}
+	if result == nil || result.Height != chain.Height() || result.Response.Code != 5 {
+		t.Fatalf("unexpected failed result %+v", result)
+	}
+
+	// rejected by CheckTx
+	chain.SetCheckTxResult(13, "sdk", "insufficient fee")
+	result, err = c.Delegate(valAddr, amount, "")
+	if err == nil {
+		t.Fatal("expect err when CheckTx rejects the tx")
+	}
+	if result == nil || len(result.TxHash) != 64 || result.Height != 0 {
+		t.Fatalf("unexpected rejected result %+v", result)
+	}
+}
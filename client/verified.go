@@ -0,0 +1,277 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/merkle"
+	"github.com/cometbft/cometbft/light"
+	"github.com/cometbft/cometbft/light/provider"
+	lightHttp "github.com/cometbft/cometbft/light/provider/http"
+	lightStore "github.com/cometbft/cometbft/light/store"
+	lightDb "github.com/cometbft/cometbft/light/store/db"
+	rpcClient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankKeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+)
+
+const (
+	defaultTrustingPeriod = 14 * 24 * time.Hour
+	// blocks to wait for the header holding the app hash of the latest state
+	verifyNextBlockAttempts = 10
+)
+
+// ErrVerifiedQueriesDisabled is returned by verified queries of a client created without
+// WithVerifiedQueries.
+var ErrVerifiedQueriesDisabled = errors.New("verified queries are not enabled")
+
+// VerifiedConfig configures the light client of verified queries. TrustedHeight and
+// TrustedHash must be taken from a source trusted out of band, not from the endpoints.
+type VerifiedConfig struct {
+	TrustedHeight int64
+	TrustedHash   []byte
+	// TrustingPeriod must be well below the unbonding period, default 14 days
+	TrustingPeriod time.Duration
+	// Witnesses cross-check the headers of the primary, the first endpoint. Default to the
+	// other endpoints, at least one is required.
+	Witnesses []string
+	// Store keeps verified headers, default in memory
+	Store lightStore.Store
+}
+
+// WithVerifiedQueries enables QueryRawContractStateVerified and QueryBalanceVerified, which
+// prove store values against the app hash of a header verified by a light client.
+func WithVerifiedQueries(cfg VerifiedConfig) Option {
+	return func(c *Client) error {
+		if cfg.TrustedHeight <= 0 || len(cfg.TrustedHash) == 0 {
+			return fmt.Errorf("trusted height and hash are required")
+		}
+		if cfg.TrustingPeriod <= 0 {
+			cfg.TrustingPeriod = defaultTrustingPeriod
+		}
+		c.verifier = &verifier{cfg: cfg, prt: rootmulti.DefaultProofRuntime()}
+		return nil
+	}
+}
+
+// verifier is nil when verified queries are disabled, the light client is created on first use.
+type verifier struct {
+	cfg VerifiedConfig
+	prt *merkle.ProofRuntime
+
+	mutex sync.Mutex
+	lc    *light.Client
+}
+
+// VerifiedValue is a store value proven against AppHash, the app hash of state Height taken
+// from the verified header Height+1. Value is nil when the key is proven absent.
+type VerifiedValue struct {
+	Height  int64
+	Key     []byte
+	Value   []byte
+	AppHash []byte
+}
+
+// QueryRawContractStateVerified returns the raw value of key in the storage of contract.
+// Smart queries are computed by the node and can't be proven, use raw state for
+// high-value decisions. A zero height means latest.
+func (c *Client) QueryRawContractStateVerified(contract string, key []byte, height int64) (*VerifiedValue, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	contractAddr, err := types.AccAddressFromBech32(contract)
+	done()
+	if err != nil {
+		return nil, err
+	}
+	storeKey := append(xWasmTypes.GetContractStorePrefix(contractAddr), key...)
+	return c.queryStoreVerified(xWasmTypes.StoreKey, storeKey, height)
+}
+
+// QueryBalanceVerified returns the balance of addr in denom with the height it was proven at.
+// The store key is built from the address bytes, no bech32 prefix is involved.
+func (c *Client) QueryBalanceVerified(addr types.AccAddress, denom string, height int64) (types.Coin, int64, error) {
+	value, err := c.queryStoreVerified(xBankTypes.StoreKey, xBankTypes.CreatePrefixedAccountStoreKey(addr, []byte(denom)), height)
+	if err != nil {
+		return types.Coin{}, 0, err
+	}
+	// zero balances are not stored
+	if value.Value == nil {
+		return types.NewCoin(denom, types.ZeroInt()), value.Height, nil
+	}
+	coin, err := xBankKeeper.UnmarshalBalanceCompat(c.clientCtx.Codec, value.Value, denom)
+	if err != nil {
+		return types.Coin{}, 0, err
+	}
+	return coin, value.Height, nil
+}
+
+func (c *Client) queryStoreVerified(storeName string, key []byte, height int64) (*VerifiedValue, error) {
+	if c.verifier == nil {
+		return nil, ErrVerifiedQueriesDisabled
+	}
+
+	cc, err := c.retryAtHeight(height, func(clientCtx client.Context) (interface{}, error) {
+		res, err := clientCtx.Client.ABCIQueryWithOptions(context.Background(), "/store/"+storeName+"/key", key,
+			rpcClient.ABCIQueryOptions{Height: clientCtx.Height, Prove: true})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Response.IsOK() {
+			return nil, fmt.Errorf("query err with code: %d, codespace: %s, log: %s",
+				res.Response.Code, res.Response.Codespace, res.Response.Log)
+		}
+		return res.Response, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := cc.(abci.ResponseQuery)
+	if height > 0 && resp.Height != height {
+		return nil, fmt.Errorf("proof is for height %d, queried %d", resp.Height, height)
+	}
+
+	appHash, err := c.verifiedAppHash(resp.Height)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyStoreProof(c.verifier.prt, appHash, storeName, key, resp); err != nil {
+		return nil, err
+	}
+	return &VerifiedValue{
+		Height:  resp.Height,
+		Key:     key,
+		Value:   resp.Value,
+		AppHash: appHash,
+	}, nil
+}
+
+// verifyStoreProof checks that resp proves the value, or absence, of key in storeName
+// under appHash.
+func verifyStoreProof(prt *merkle.ProofRuntime, appHash []byte, storeName string, key []byte, resp abci.ResponseQuery) error {
+	if resp.ProofOps == nil || len(resp.ProofOps.Ops) == 0 {
+		return fmt.Errorf("no proof ops")
+	}
+	if resp.Height <= 0 {
+		return fmt.Errorf("invalid proof height %d", resp.Height)
+	}
+	if !bytes.Equal(resp.Key, key) {
+		return fmt.Errorf("proof is for key %X, queried %X", resp.Key, key)
+	}
+
+	keyPath := merkle.KeyPath{}.
+		AppendKey([]byte(storeName), merkle.KeyEncodingURL).
+		AppendKey(key, merkle.KeyEncodingHex).
+		String()
+	if resp.Value != nil {
+		if err := prt.VerifyValue(resp.ProofOps, appHash, keyPath, resp.Value); err != nil {
+			return fmt.Errorf("verify value proof err: %w", err)
+		}
+		return nil
+	}
+	if err := prt.VerifyAbsence(resp.ProofOps, appHash, keyPath); err != nil {
+		return fmt.Errorf("verify absence proof err: %w", err)
+	}
+	return nil
+}
+
+// verifiedAppHash returns the app hash of state height from the light client verified
+// header height+1, waiting for that block when height is the latest one.
+func (c *Client) verifiedAppHash(height int64) ([]byte, error) {
+	lc, err := c.lightClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitLightBlock(context.Background(), lc.Primary(), height+1); err != nil {
+		return nil, err
+	}
+
+	lightBlock, err := lc.VerifyLightBlockAtHeight(context.Background(), height+1, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("verify header %d err: %w", height+1, err)
+	}
+	return lightBlock.AppHash, nil
+}
+
+// waitLightBlock waits until the primary of the light client has the block at height.
+// Other endpoints may be ahead of it, and asking the light client for a height its primary
+// doesn't have yet makes it drop the primary. Errors other than a height too high are left
+// to the verification.
+func waitLightBlock(ctx context.Context, primary provider.Provider, height int64) error {
+	for i := 0; ; i++ {
+		_, err := primary.LightBlock(ctx, height)
+		if !errors.Is(err, provider.ErrHeightTooHigh) {
+			return nil
+		}
+		if i >= verifyNextBlockAttempts {
+			return fmt.Errorf("block %d not produced yet", height)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitTime):
+		}
+	}
+}
+
+func (c *Client) lightClient() (*light.Client, error) {
+	v := c.verifier
+	if v == nil {
+		return nil, ErrVerifiedQueriesDisabled
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.lc != nil {
+		return v.lc, nil
+	}
+
+	chainId := c.clientCtx.ChainID
+	primary, err := lightHttp.New(chainId, c.endpointUrl(0))
+	if err != nil {
+		return nil, err
+	}
+	witnessUrls := v.cfg.Witnesses
+	if len(witnessUrls) == 0 {
+		for i := 1; i < len(c.rpcClientList); i++ {
+			witnessUrls = append(witnessUrls, c.endpointUrl(i))
+		}
+	}
+	if len(witnessUrls) == 0 {
+		return nil, fmt.Errorf("verified queries need at least one witness")
+	}
+	witnesses := make([]provider.Provider, 0, len(witnessUrls))
+	for _, url := range witnessUrls {
+		witness, err := lightHttp.New(chainId, url)
+		if err != nil {
+			return nil, err
+		}
+		witnesses = append(witnesses, witness)
+	}
+	store := v.cfg.Store
+	if store == nil {
+		store = lightDb.New(dbm.NewMemDB(), chainId)
+	}
+
+	lc, err := light.NewClient(context.Background(), chainId,
+		light.TrustOptions{
+			Period: v.cfg.TrustingPeriod,
+			Height: v.cfg.TrustedHeight,
+			Hash:   v.cfg.TrustedHash,
+		},
+		primary, witnesses, store)
+	if err != nil {
+		return nil, fmt.Errorf("create light client err: %w", err)
+	}
+	v.lc = lc
+	return lc, nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	xWasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtLog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/light/provider"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	storeTypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	xBankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+func TestVerifyStoreProof(t *testing.T) {
+	wasmKey := storeTypes.NewKVStoreKey(xWasmTypes.StoreKey)
+	bankKey := storeTypes.NewKVStoreKey(xBankTypes.StoreKey)
+	store := rootmulti.NewStore(dbm.NewMemDB(), cmtLog.NewNopLogger())
+	store.MountStoreWithDB(wasmKey, storeTypes.StoreTypeIAVL, nil)
+	store.MountStoreWithDB(bankKey, storeTypes.StoreTypeIAVL, nil)
+	if err := store.LoadLatestVersion(); err != nil {
+		t.Fatal(err)
+	}
+
+	contract := types.AccAddress([]byte("contract____________"))
+	stateKey := append(xWasmTypes.GetContractStorePrefix(contract), []byte("config")...)
+	store.GetKVStore(wasmKey).Set(stateKey, []byte(`{"era":12}`))
+	store.GetKVStore(bankKey).Set(xBankTypes.CreatePrefixedAccountStoreKey(contract, []byte("untrn")), []byte("100"))
+	commit := store.Commit()
+
+	query := func(storeName string, key []byte) abci.ResponseQuery {
+		return store.Query(abci.RequestQuery{Path: "/" + storeName + "/key", Data: key, Height: commit.Version, Prove: true})
+	}
+	prt := rootmulti.DefaultProofRuntime()
+
+	resp := query(xWasmTypes.StoreKey, stateKey)
+	if err := verifyStoreProof(prt, commit.Hash, xWasmTypes.StoreKey, stateKey, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// a node lying about the value
+	tampered := resp
+	tampered.Value = []byte(`{"era":13}`)
+	if err := verifyStoreProof(prt, commit.Hash, xWasmTypes.StoreKey, stateKey, tampered); err == nil {
+		t.Fatal("tampered value verified")
+	}
+	// a node hiding the value
+	tampered.Value = nil
+	if err := verifyStoreProof(prt, commit.Hash, xWasmTypes.StoreKey, stateKey, tampered); err == nil {
+		t.Fatal("hidden value verified as absent")
+	}
+	// a proof of another store
+	if err := verifyStoreProof(prt, commit.Hash, xBankTypes.StoreKey, stateKey, resp); err == nil {
+		t.Fatal("value verified in the wrong store")
+	}
+	if err := verifyStoreProof(prt, []byte("other app hash"), xWasmTypes.StoreKey, stateKey, resp); err == nil {
+		t.Fatal("value verified against another app hash")
+	}
+
+	absentKey := xBankTypes.CreatePrefixedAccountStoreKey(contract, []byte("uatom"))
+	resp = query(xBankTypes.StoreKey, absentKey)
+	if resp.Value != nil {
+		t.Fatalf("unexpected value %s", resp.Value)
+	}
+	if err := verifyStoreProof(prt, commit.Hash, xBankTypes.StoreKey, absentKey, resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifiedQueriesDisabled(t *testing.T) {
+	c := &Client{}
+	if _, err := c.queryStoreVerified(xBankTypes.StoreKey, []byte("key"), 0); !errors.Is(err, ErrVerifiedQueriesDisabled) {
+		t.Fatalf("got err %v, want ErrVerifiedQueriesDisabled", err)
+	}
+	if err := WithVerifiedQueries(VerifiedConfig{TrustedHeight: 10})(c); err == nil {
+		t.Fatal("config without trusted hash accepted")
+	}
+}
+
+// laggingProvider is a light client primary that has blocks up to latest.
+type laggingProvider struct {
+	latest int64
+	calls  int
+}
+
+func (p *laggingProvider) ChainID() string { return "neutron-test-1" }
+
+func (p *laggingProvider) LightBlock(_ context.Context, height int64) (*cmtTypes.LightBlock, error) {
+	p.calls++
+	if height > p.latest {
+		// the block is produced after the first ask
+		p.latest++
+		return nil, provider.ErrHeightTooHigh
+	}
+	return &cmtTypes.LightBlock{}, nil
+}
+
+func (p *laggingProvider) ReportEvidence(context.Context, cmtTypes.Evidence) error { return nil }
+
+func TestWaitLightBlock(t *testing.T) {
+	// the primary is asked again until it has the block, whatever the other endpoints have
+	primary := &laggingProvider{latest: 9}
+	if err := waitLightBlock(context.Background(), primary, 10); err != nil {
+		t.Fatal(err)
+	}
+	if primary.calls != 2 {
+		t.Fatalf("primary asked %d times, want 2", primary.calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitLightBlock(ctx, &laggingProvider{latest: 5}, 10); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err %v", err)
+	}
+}
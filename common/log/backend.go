@@ -0,0 +1,196 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+	"github.com/sirupsen/logrus"
+)
+
+// Output formats of a Backend. FormatLogfmt is FormatText with empty values quoted, so every
+// field stays a key=value pair for logfmt parsers.
+const (
+	FormatText   = "text"
+	FormatLogfmt = "logfmt"
+	FormatJSON   = "json"
+)
+
+var ErrBackendClosed = errors.New("log backend closed")
+
+const generalModule = "general"
+
+type Config struct {
+	// Dir holds one rotated file per module, logs only go to the console when empty
+	Dir string
+	// Format is FormatText, FormatLogfmt or FormatJSON, FormatText by default
+	Format string
+	// Level is the default level name, "info" by default
+	Level string
+	// ModuleLevels overrides Level for the named modules
+	ModuleLevels map[string]string
+	// RotationTime and MaxAge default to one day and seven days
+	RotationTime time.Duration
+	MaxAge       time.Duration
+	// Console also writes every module to stdout
+	Console bool
+}
+
+// Backend creates loggers that write each module to its own persistent, rotated file with
+// its own level. Unlike InitLogFile it keeps no global logrus state.
+type Backend struct {
+	cfg       Config
+	formatter logrus.Formatter
+	level     logrus.Level
+
+	mutex   sync.Mutex
+	modules map[string]*logrus.Logger
+	writers []*rotatelogs.RotateLogs
+	closed  bool
+}
+
+func NewBackend(cfg Config) (*Backend, error) {
+	var formatter logrus.Formatter
+	switch cfg.Format {
+	case "", FormatText:
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	case FormatLogfmt:
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true, QuoteEmptyFields: true}
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("unknown log format %s", cfg.Format)
+	}
+
+	level := logrus.InfoLevel
+	if len(cfg.Level) != 0 {
+		var err error
+		if level, err = logrus.ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	for module, moduleLevel := range cfg.ModuleLevels {
+		if _, err := logrus.ParseLevel(moduleLevel); err != nil {
+			return nil, fmt.Errorf("module %s: %w", module, err)
+		}
+	}
+	if cfg.RotationTime <= 0 {
+		cfg.RotationTime = time.Duration(rotationTime) * time.Second
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = time.Duration(maxAge) * time.Second
+	}
+	if len(cfg.Dir) != 0 {
+		if err := os.MkdirAll(cfg.Dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := clearLockFiles(cfg.Dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Backend{
+		cfg:       cfg,
+		formatter: formatter,
+		level:     level,
+		modules:   make(map[string]*logrus.Logger),
+	}, nil
+}
+
+// Logger returns a logger of module with the given context key/value pairs.
+func (b *Backend) Logger(module string, ctx ...interface{}) (Logger, error) {
+	if len(module) == 0 {
+		module = generalModule
+	}
+	l, err := b.moduleLogger(module)
+	if err != nil {
+		return nil, err
+	}
+	return &log{l.WithFields(transField(append([]interface{}{"module", module}, ctx...)))}, nil
+}
+
+func (b *Backend) moduleLogger(module string) (*logrus.Logger, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, ErrBackendClosed
+	}
+	if l, ok := b.modules[module]; ok {
+		return l, nil
+	}
+
+	writers := make([]io.Writer, 0, 2)
+	if len(b.cfg.Dir) != 0 {
+		writer, err := rotatelogs.New(
+			filepath.Join(b.cfg.Dir, module)+".%Y%m%d",
+			rotatelogs.WithMaxAge(b.cfg.MaxAge),
+			rotatelogs.WithRotationTime(b.cfg.RotationTime),
+		)
+		if err != nil {
+			return nil, err
+		}
+		b.writers = append(b.writers, writer)
+		writers = append(writers, writer)
+	}
+	if b.cfg.Console || len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+
+	level := b.level
+	if moduleLevel, ok := b.cfg.ModuleLevels[module]; ok {
+		// validated in NewBackend
+		level, _ = logrus.ParseLevel(moduleLevel)
+	}
+	l := logrus.New()
+	l.SetOutput(io.MultiWriter(writers...))
+	l.SetFormatter(b.formatter)
+	l.SetLevel(level)
+	b.modules[module] = l
+	return l, nil
+}
+
+// SetModuleLevel changes the level of module at runtime.
+func (b *Backend) SetModuleLevel(module, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l, err := b.moduleLogger(module)
+	if err != nil {
+		return err
+	}
+	l.SetLevel(lvl)
+	return nil
+}
+
+// Close closes the files of all modules. Loggers created before discard their entries
+// afterwards, Logger and SetModuleLevel return ErrBackendClosed.
+func (b *Backend) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	// a rotated writer reopens its file on the next write
+	for _, l := range b.modules {
+		l.SetOutput(io.Discard)
+	}
+	b.modules = nil
+
+	var firstErr error
+	for _, writer := range b.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	b.writers = nil
+	return firstErr
+}
@@ -0,0 +1,119 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackend(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewBackend(Config{
+		Dir:          dir,
+		Format:       FormatJSON,
+		Level:        "info",
+		ModuleLevels: map[string]string{"client": "debug"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientLog, err := b.Logger("client", "chain", "neutron-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scannerLog, err := b.Logger("scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	With(clientLog, "endpoint index", 1).Debug("retry:", "err", "timeout")
+	scannerLog.Debug("dropped")
+	scannerLog.Info("kept")
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	suffix := "." + time.Now().Format("20060102")
+	data, err := os.ReadFile(filepath.Join(dir, "client"+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := map[string]interface{}{}
+	if err := json.Unmarshal(data, &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "retry:" || line["chain"] != "neutron-1" || line["endpoint index"] != float64(1) || line["module"] != "client" {
+		t.Fatalf("unexpected line %v", line)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "scanner"+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dropped") || !strings.Contains(string(data), "kept") {
+		t.Fatalf("unexpected scanner log %s", data)
+	}
+}
+
+func TestBackendClose(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewBackend(Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := b.Logger("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before")
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+
+	data, err := os.ReadFile(filepath.Join(dir, "client."+time.Now().Format("20060102")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before") || strings.Contains(string(data), "after") {
+		t.Fatalf("unexpected log %s", data)
+	}
+	if _, err := b.Logger("client"); !errors.Is(err, ErrBackendClosed) {
+		t.Fatalf("got %v, want backend closed", err)
+	}
+	if err := b.SetModuleLevel("client", "debug"); !errors.Is(err, ErrBackendClosed) {
+		t.Fatalf("got %v, want backend closed", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackendLogfmt(t *testing.T) {
+	for format, want := range map[string]string{FormatText: "memo= ", FormatLogfmt: `memo="" `} {
+		dir := t.TempDir()
+		b, err := NewBackend(Config{Dir: dir, Format: format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := b.Logger("client")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Info("sent", "memo", "")
+		if err := b.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "client."+time.Now().Format("20060102")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s: %s has no %s", format, data, want)
+		}
+	}
+}
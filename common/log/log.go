@@ -39,10 +39,11 @@ func InitLogFile(logPath string) error {
 type BtmHook struct {
 	logPath string
 	lock    *sync.Mutex
+	writers map[string]*rotatelogs.RotateLogs
 }
 
 func newBtmHook(logPath string) *BtmHook {
-	hook := &BtmHook{lock: new(sync.Mutex)}
+	hook := &BtmHook{lock: new(sync.Mutex), writers: make(map[string]*rotatelogs.RotateLogs)}
 	hook.logPath = logPath
 	return hook
 }
@@ -54,14 +55,19 @@ func (hook *BtmHook) ioWrite(entry *logrus.Entry) error {
 		module = data.(string)
 	}
 
-	logPath := filepath.Join(hook.logPath, module)
-	writer, err := rotatelogs.New(
-		logPath+".%Y%m%d",
-		rotatelogs.WithMaxAge(time.Duration(maxAge)*time.Second),
-		rotatelogs.WithRotationTime(time.Duration(rotationTime)*time.Second),
-	)
-	if err != nil {
-		return err
+	// writers stay open, rotatelogs switches files itself
+	writer, ok := hook.writers[module]
+	if !ok {
+		var err error
+		writer, err = rotatelogs.New(
+			filepath.Join(hook.logPath, module)+".%Y%m%d",
+			rotatelogs.WithMaxAge(time.Duration(maxAge)*time.Second),
+			rotatelogs.WithRotationTime(time.Duration(rotationTime)*time.Second),
+		)
+		if err != nil {
+			return err
+		}
+		hook.writers[module] = writer
 	}
 
 	msg, err := defaultFormatterFileUse.Format(entry)
@@ -69,11 +75,8 @@ func (hook *BtmHook) ioWrite(entry *logrus.Entry) error {
 		return err
 	}
 
-	if _, err = writer.Write(msg); err != nil {
-		return err
-	}
-
-	return writer.Close()
+	_, err = writer.Write(msg)
+	return err
 }
 
 func clearLockFiles(logPath string) error {
@@ -14,6 +14,21 @@ type Logger interface {
 	Error(msg string, ctx ...interface{})
 }
 
+// contextLogger is a Logger that derives child loggers itself, the loggers of this package
+// implement it.
+type contextLogger interface {
+	With(ctx ...interface{}) Logger
+}
+
+// With returns a child of l that adds ctx to every message. Loggers without a With method
+// get ctx appended to the ctx of every call instead.
+func With(l Logger, ctx ...interface{}) Logger {
+	if cl, ok := l.(contextLogger); ok {
+		return cl.With(ctx...)
+	}
+	return &withLog{logger: l, ctx: ctx}
+}
+
 type log struct {
 	entry *logrus.Entry
 }
@@ -42,6 +57,43 @@ func (l *log) Error(msg string, ctx ...interface{}) {
 	l.entry.WithFields(transField(ctx)).Error(msg)
 }
 
+func (l *log) With(ctx ...interface{}) Logger {
+	return &log{l.entry.WithFields(transField(ctx))}
+}
+
+type withLog struct {
+	logger Logger
+	ctx    []interface{}
+}
+
+func (l *withLog) Trace(msg string, ctx ...interface{}) {
+	l.logger.Trace(msg, l.join(ctx)...)
+}
+
+func (l *withLog) Debug(msg string, ctx ...interface{}) {
+	l.logger.Debug(msg, l.join(ctx)...)
+}
+
+func (l *withLog) Info(msg string, ctx ...interface{}) {
+	l.logger.Info(msg, l.join(ctx)...)
+}
+
+func (l *withLog) Warn(msg string, ctx ...interface{}) {
+	l.logger.Warn(msg, l.join(ctx)...)
+}
+
+func (l *withLog) Error(msg string, ctx ...interface{}) {
+	l.logger.Error(msg, l.join(ctx)...)
+}
+
+func (l *withLog) With(ctx ...interface{}) Logger {
+	return &withLog{logger: l.logger, ctx: l.join(ctx)}
+}
+
+func (l *withLog) join(ctx []interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(l.ctx)+len(ctx)), l.ctx...), ctx...)
+}
+
 func transField(datas []interface{}) logrus.Fields {
 	field := make(logrus.Fields)
 	for i := 0; i < len(datas); i += 2 {
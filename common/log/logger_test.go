@@ -0,0 +1,36 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+)
+
+// plainLogger is a Logger of another package, without a With method.
+type plainLogger struct {
+	calls [][]interface{}
+}
+
+func (l *plainLogger) Trace(msg string, ctx ...interface{}) { l.record(msg, ctx) }
+func (l *plainLogger) Debug(msg string, ctx ...interface{}) { l.record(msg, ctx) }
+func (l *plainLogger) Info(msg string, ctx ...interface{})  { l.record(msg, ctx) }
+func (l *plainLogger) Warn(msg string, ctx ...interface{})  { l.record(msg, ctx) }
+func (l *plainLogger) Error(msg string, ctx ...interface{}) { l.record(msg, ctx) }
+
+func (l *plainLogger) record(msg string, ctx []interface{}) {
+	l.calls = append(l.calls, append([]interface{}{msg}, ctx...))
+}
+
+func TestWithPlainLogger(t *testing.T) {
+	plain := &plainLogger{}
+	l := With(With(plain, "module", "client"), "endpoint index", 1)
+	l.Info("retry:", "err", "timeout")
+	With(plain, "module", "scanner").Warn("lagging")
+
+	want := [][]interface{}{
+		{"retry:", "module", "client", "endpoint index", 1, "err", "timeout"},
+		{"lagging", "module", "scanner"},
+	}
+	if !reflect.DeepEqual(plain.calls, want) {
+		t.Fatalf("got calls %v, want %v", plain.calls, want)
+	}
+}
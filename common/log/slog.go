@@ -0,0 +1,122 @@
+//go:build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// LevelTrace is the slog level of Trace messages.
+const LevelTrace = slog.LevelDebug - 4
+
+type slogLog struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger implements Logger on top of l.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLog{l}
+}
+
+func (l *slogLog) Trace(msg string, ctx ...interface{}) {
+	l.logger.Log(context.Background(), LevelTrace, msg, ctx...)
+}
+
+func (l *slogLog) Debug(msg string, ctx ...interface{}) {
+	l.logger.Debug(msg, ctx...)
+}
+
+func (l *slogLog) Info(msg string, ctx ...interface{}) {
+	l.logger.Info(msg, ctx...)
+}
+
+func (l *slogLog) Warn(msg string, ctx ...interface{}) {
+	l.logger.Warn(msg, ctx...)
+}
+
+func (l *slogLog) Error(msg string, ctx ...interface{}) {
+	l.logger.Error(msg, ctx...)
+}
+
+func (l *slogLog) With(ctx ...interface{}) Logger {
+	return &slogLog{l.logger.With(ctx...)}
+}
+
+// slogHandler sends slog records to a Logger, attrs of groups are flattened to
+// "group.key" keys.
+type slogHandler struct {
+	logger Logger
+	level  slog.Leveler
+	prefix string
+}
+
+// NewSlogHandler exposes l as a slog.Handler, records below level are dropped. The level of
+// l itself still applies.
+func NewSlogHandler(l Logger, level slog.Leveler) slog.Handler {
+	if level == nil {
+		level = LevelTrace
+	}
+	return &slogHandler{logger: l, level: level}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	ctx := make([]interface{}, 0, record.NumAttrs()*2)
+	record.Attrs(func(attr slog.Attr) bool {
+		ctx = appendAttr(ctx, h.prefix, attr)
+		return true
+	})
+
+	switch {
+	case record.Level >= slog.LevelError:
+		h.logger.Error(record.Message, ctx...)
+	case record.Level >= slog.LevelWarn:
+		h.logger.Warn(record.Message, ctx...)
+	case record.Level >= slog.LevelInfo:
+		h.logger.Info(record.Message, ctx...)
+	case record.Level >= slog.LevelDebug:
+		h.logger.Debug(record.Message, ctx...)
+	default:
+		h.logger.Trace(record.Message, ctx...)
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ctx := make([]interface{}, 0, len(attrs)*2)
+	for _, attr := range attrs {
+		ctx = appendAttr(ctx, h.prefix, attr)
+	}
+	return &slogHandler{logger: With(h.logger, ctx...), level: h.level, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	return &slogHandler{logger: h.logger, level: h.level, prefix: h.prefix + name + "."}
+}
+
+func appendAttr(ctx []interface{}, prefix string, attr slog.Attr) []interface{} {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		// attrs of an inline group without key belong to the parent
+		if len(attr.Key) != 0 {
+			groupPrefix = fmt.Sprintf("%s%s.", prefix, attr.Key)
+		}
+		for _, groupAttr := range value.Group() {
+			ctx = appendAttr(ctx, groupPrefix, groupAttr)
+		}
+		return ctx
+	}
+	if attr.Equal(slog.Attr{}) {
+		return ctx
+	}
+	return append(ctx, prefix+attr.Key, value.Any())
+}
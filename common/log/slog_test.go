@@ -0,0 +1,29 @@
+//go:build go1.21
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogAdapters(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: LevelTrace})))
+
+	// an sdk logger exposed as a handler and wrapped again must keep groups and attrs
+	l := slog.New(NewSlogHandler(With(logger, "module", "client"), slog.LevelDebug))
+	l.WithGroup("rpc").Debug("retry:", "endpoint index", 2)
+	l.Log(context.Background(), LevelTrace, "dropped")
+
+	line := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%s: %v", buf.String(), err)
+	}
+	if line["level"] != "DEBUG" || line["msg"] != "retry:" || line["module"] != "client" || line["rpc.endpoint index"] != float64(2) {
+		t.Fatalf("unexpected line %v", line)
+	}
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type zapLog struct {
+	sugar *zap.SugaredLogger
+}
+
+// NewZapLogger implements Logger on top of l, Trace is logged at debug level as zap has no
+// lower level.
+func NewZapLogger(l *zap.Logger) Logger {
+	return &zapLog{l.Sugar()}
+}
+
+func (l *zapLog) Trace(msg string, ctx ...interface{}) {
+	l.sugar.Debugw(msg, zapFields(ctx)...)
+}
+
+func (l *zapLog) Debug(msg string, ctx ...interface{}) {
+	l.sugar.Debugw(msg, zapFields(ctx)...)
+}
+
+func (l *zapLog) Info(msg string, ctx ...interface{}) {
+	l.sugar.Infow(msg, zapFields(ctx)...)
+}
+
+func (l *zapLog) Warn(msg string, ctx ...interface{}) {
+	l.sugar.Warnw(msg, zapFields(ctx)...)
+}
+
+func (l *zapLog) Error(msg string, ctx ...interface{}) {
+	l.sugar.Errorw(msg, zapFields(ctx)...)
+}
+
+func (l *zapLog) With(ctx ...interface{}) Logger {
+	return &zapLog{l.sugar.With(zapFields(ctx)...)}
+}
+
+// zapFields makes ctx valid key/value pairs for the sugared logger like transField does for
+// logrus: keys are formatted as strings and a trailing value without key is keyed "field".
+// The sugared logger would drop them and DPanic otherwise.
+func zapFields(ctx []interface{}) []interface{} {
+	fields := make([]interface{}, 0, len(ctx)+1)
+	for i := 0; i < len(ctx); i += 2 {
+		if i+1 == len(ctx) {
+			fields = append(fields, "field", ctx[i])
+			break
+		}
+		key, ok := ctx[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", ctx[i])
+		}
+		fields = append(fields, key, ctx[i+1])
+	}
+	return fields
+}
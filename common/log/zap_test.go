@@ -0,0 +1,27 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	// development loggers panic on invalid key/value pairs
+	l := With(NewZapLogger(zap.New(core, zap.Development())), "module", "client", 7, "index")
+
+	l.Trace("trace")
+	l.Info("retry:", "endpoint index", 1, "dangling")
+	l.Warn("odd")
+
+	entries := logs.All()
+	if len(entries) != 3 || entries[0].Level != zap.DebugLevel {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	fields := entries[1].ContextMap()
+	if fields["module"] != "client" || fields["7"] != "index" || fields["endpoint index"] != int64(1) || fields["field"] != "dangling" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
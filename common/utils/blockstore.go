@@ -5,8 +5,8 @@ package utils
 
 import (
 	"fmt"
-	"io/ioutil"
 	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,13 +14,32 @@ import (
 
 const PathPostfix = ".chainbridge/blockstore"
 
+// Keys of the latest block and signature in a CursorStorer
+const (
+	BlockCursor     = "block"
+	SignatureCursor = "signature"
+)
+
 type Blockstorer interface {
 	StoreBlock(*big.Int) error
 	StoreSignature(string) error
 }
 
+// CursorStorer is a Blockstorer that also keeps arbitrary named cursors, e.g. one per
+// scanned stream. GetCursor returns found false if the cursor was never put.
+type CursorStorer interface {
+	Blockstorer
+	TryLoadLatestBlock() (*big.Int, error)
+	TryLoadLatestSignature() (string, error)
+	GetCursor(name string) (value string, found bool, err error)
+	PutCursor(name string, value string) error
+}
+
+// migratedCursor marks a Blockstore whose block file no longer holds a legacy signature
+const migratedCursor = "blockstore_migrated"
+
 var _ Blockstorer = &EmptyStore{}
-var _ Blockstorer = &Blockstore{}
+var _ CursorStorer = &Blockstore{}
 
 // Dummy store for testing only
 type EmptyStore struct{}
@@ -28,7 +47,8 @@ type EmptyStore struct{}
 func (s *EmptyStore) StoreBlock(_ *big.Int) error   { return nil }
 func (s *EmptyStore) StoreSignature(_ string) error { return nil }
 
-// Blockstore implements Blockstorer.
+// Blockstore implements CursorStorer with one file per cursor. Files are replaced with an
+// atomic write-rename, so a crash never leaves a partially written cursor behind.
 type Blockstore struct {
 	path     string // Path excluding filename
 	fullPath string
@@ -56,80 +76,126 @@ func NewBlockstore(path string, chain uint8, relayer string) (*Blockstore, error
 
 // StoreBlock writes the block number to disk.
 func (b *Blockstore) StoreBlock(block *big.Int) error {
-	// Create dir if it does not exist
-	if _, err := os.Stat(b.path); os.IsNotExist(err) {
-		errr := os.MkdirAll(b.path, os.ModePerm)
-		if errr != nil {
-			return errr
-		}
+	return b.PutCursor(BlockCursor, block.String())
+}
+
+// StoreSignature writes the signature to disk, apart from the block.
+func (b *Blockstore) StoreSignature(sig string) error {
+	return b.PutCursor(SignatureCursor, sig)
+}
+
+// TryLoadLatestSignature will attempt to load the latest signature for the chain/relayer pair, returning "" if not found.
+// Stores written before cursors had the signature in the block file, it is moved to the
+// signature cursor once, unless a block was stored by this version first.
+func (b *Blockstore) TryLoadLatestSignature() (string, error) {
+	sig, found, err := b.GetCursor(SignatureCursor)
+	if err != nil || found {
+		return sig, err
+	}
+	migrated, err := fileExists(b.cursorPath(migratedCursor))
+	if err != nil || migrated {
+		return "", err
 	}
+	sig, found, err = b.GetCursor(BlockCursor)
+	if err != nil || !found {
+		return "", err
+	}
+	if err := b.StoreSignature(sig); err != nil {
+		return "", err
+	}
+	return sig, b.markMigrated()
+}
+
+// TryLoadLatestBlock will attempt to load the latest block for the chain/relayer pair, returning 0 if not found.
+// Passing an empty string for path will cause it to use the home directory.
+func (b *Blockstore) TryLoadLatestBlock() (*big.Int, error) {
+	return tryLoadBlock(b)
+}
 
-	// Write bytes to file
-	data := []byte(block.String())
-	err := ioutil.WriteFile(b.fullPath, data, 0600)
+func (b *Blockstore) GetCursor(name string) (string, bool, error) {
+	dat, err := os.ReadFile(b.cursorPath(name))
 	if err != nil {
-		return err
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
 	}
-	return nil
+	return string(dat), true, nil
 }
 
-// StoreBlock writes the signature  to disk.
-func (b *Blockstore) StoreSignature(sig string) error {
-	// Create dir if it does not exist
-	if _, err := os.Stat(b.path); os.IsNotExist(err) {
-		errr := os.MkdirAll(b.path, os.ModePerm)
-		if errr != nil {
-			return errr
+func (b *Blockstore) PutCursor(name string, value string) error {
+	if err := os.MkdirAll(b.path, os.ModePerm); err != nil {
+		return err
+	}
+	if name == BlockCursor {
+		if err := b.markMigrated(); err != nil {
+			return err
 		}
 	}
+	return WriteFileAtomic(b.cursorPath(name), []byte(value), 0600)
+}
 
-	// Write bytes to file
-	data := []byte(sig)
-	err := ioutil.WriteFile(b.fullPath, data, 0600)
-	if err != nil {
+// markMigrated records that the block file holds only blocks, so TryLoadLatestSignature no
+// longer takes it for a legacy signature.
+func (b *Blockstore) markMigrated() error {
+	fileName := b.cursorPath(migratedCursor)
+	exists, err := fileExists(fileName)
+	if err != nil || exists {
 		return err
 	}
-	return nil
+	return WriteFileAtomic(fileName, nil, 0600)
 }
 
-// TryLoadLatestBlock will attempt to load the latest block for the chain/relayer pair, returning 0 if not found.
-// Passing an empty string for path will cause it to use the home directory.
-func (b *Blockstore) TryLoadLatestSignature() (string, error) {
-	// If it exists, load and return
-	exists, err := fileExists(b.fullPath)
+// cursorPath keeps the block at the legacy file name so existing stores are picked up. Names
+// are query escaped, which is reversible so distinct names never share a file.
+func (b *Blockstore) cursorPath(name string) string {
+	if name == BlockCursor {
+		return b.fullPath
+	}
+	return fmt.Sprintf("%s.%s", b.fullPath, url.QueryEscape(name))
+}
+
+// WriteFileAtomic writes data to a uniquely named temp file in the same dir, syncs it and renames it over fileName.
+func WriteFileAtomic(fileName string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".tmp*")
 	if err != nil {
-		return "", err
+		return err
 	}
-	if exists {
-		dat, err := ioutil.ReadFile(b.fullPath)
-		if err != nil {
-			return "", err
-		}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op after a successful rename
 
-		return string(dat), nil
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
 	}
-	// Otherwise just return 0
-	return "", nil
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, fileName)
 }
 
-// TryLoadLatestBlock will attempt to load the latest block for the chain/relayer pair, returning 0 if not found.
-// Passing an empty string for path will cause it to use the home directory.
-func (b *Blockstore) TryLoadLatestBlock() (*big.Int, error) {
-	// If it exists, load and return
-	exists, err := fileExists(b.fullPath)
+// tryLoadBlock parses the block cursor of s, returning 0 if not found and an error if the
+// stored value is not a number.
+func tryLoadBlock(s CursorStorer) (*big.Int, error) {
+	value, found, err := s.GetCursor(BlockCursor)
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		dat, err := ioutil.ReadFile(b.fullPath)
-		if err != nil {
-			return nil, err
-		}
-		block, _ := big.NewInt(0).SetString(string(dat), 10)
-		return block, nil
+	if !found {
+		return big.NewInt(0), nil
+	}
+	block, ok := big.NewInt(0).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("stored block %q is not a number", value)
 	}
-	// Otherwise just return 0
-	return big.NewInt(0), nil
+	return block, nil
 }
 
 func getFileName(chain uint8, relayer string) string {
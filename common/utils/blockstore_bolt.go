@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ CursorStorer = &BoltBlockstore{}
+
+// BoltBlockstore implements CursorStorer in an embedded bbolt database, cursors of a
+// chain/relayer pair live in their own bucket so several relayers can share one file.
+type BoltBlockstore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// NewBoltBlockstore opens or creates the database file dbPath, an empty dbPath uses
+// blockstore.db under the default path.
+func NewBoltBlockstore(dbPath string, chain uint8, relayer string) (*BoltBlockstore, error) {
+	if dbPath == "" {
+		def, err := getDefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		dbPath = filepath.Join(def, "blockstore.db")
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open %s err: %w", dbPath, err)
+	}
+
+	bucket := []byte(getFileName(chain, relayer))
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltBlockstore{db: db, bucket: bucket}, nil
+}
+
+func (b *BoltBlockstore) Close() error {
+	return b.db.Close()
+}
+
+func (b *BoltBlockstore) StoreBlock(block *big.Int) error {
+	return b.PutCursor(BlockCursor, block.String())
+}
+
+func (b *BoltBlockstore) StoreSignature(sig string) error {
+	return b.PutCursor(SignatureCursor, sig)
+}
+
+func (b *BoltBlockstore) TryLoadLatestBlock() (*big.Int, error) {
+	return tryLoadBlock(b)
+}
+
+func (b *BoltBlockstore) TryLoadLatestSignature() (string, error) {
+	sig, _, err := b.GetCursor(SignatureCursor)
+	return sig, err
+}
+
+func (b *BoltBlockstore) GetCursor(name string) (string, bool, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// the returned slice is only valid inside the tx
+		if v := tx.Bucket(b.bucket).Get([]byte(name)); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return string(value), value != nil, nil
+}
+
+func (b *BoltBlockstore) PutCursor(name string, value string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(name), []byte(value))
+	})
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// SQL dialects, they differ in bind variables and upsert syntax
+const (
+	DialectMySQL = iota
+	DialectPostgres
+	DialectSQLite
+)
+
+var _ CursorStorer = &SQLBlockstore{}
+
+var sqlTableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLBlockstore implements CursorStorer in a table of a database/sql database, the caller
+// opens the db with the driver of its choice. Rows are keyed by the chain/relayer pair and
+// the cursor name, so several relayers can share one table.
+type SQLBlockstore struct {
+	db      *sql.DB
+	table   string
+	store   string
+	dialect int
+}
+
+// NewSQLBlockstore creates table if it doesn't exist, dialect is one of DialectMySQL,
+// DialectPostgres and DialectSQLite.
+func NewSQLBlockstore(db *sql.DB, table string, dialect int, chain uint8, relayer string) (*SQLBlockstore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	if !sqlTableNameRegexp.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	if dialect != DialectMySQL && dialect != DialectPostgres && dialect != DialectSQLite {
+		return nil, fmt.Errorf("unknown sql dialect %d", dialect)
+	}
+
+	_, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	store VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (store, name)
+)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("create table %s err: %w", table, err)
+	}
+	return &SQLBlockstore{
+		db:      db,
+		table:   table,
+		store:   getFileName(chain, relayer),
+		dialect: dialect,
+	}, nil
+}
+
+func (s *SQLBlockstore) StoreBlock(block *big.Int) error {
+	return s.PutCursor(BlockCursor, block.String())
+}
+
+func (s *SQLBlockstore) StoreSignature(sig string) error {
+	return s.PutCursor(SignatureCursor, sig)
+}
+
+func (s *SQLBlockstore) TryLoadLatestBlock() (*big.Int, error) {
+	return tryLoadBlock(s)
+}
+
+func (s *SQLBlockstore) TryLoadLatestSignature() (string, error) {
+	sig, _, err := s.GetCursor(SignatureCursor)
+	return sig, err
+}
+
+func (s *SQLBlockstore) GetCursor(name string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRow(s.query("SELECT value FROM %s WHERE store = %s AND name = %s"), s.store, name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// PutCursor upserts the row in one statement, concurrent writers of a missing row don't
+// conflict.
+func (s *SQLBlockstore) PutCursor(name string, value string) error {
+	upsert := "INSERT INTO %s (store, name, value) VALUES (%s, %s, %s) ON CONFLICT (store, name) DO UPDATE SET value = excluded.value"
+	if s.dialect == DialectMySQL {
+		upsert = "INSERT INTO %s (store, name, value) VALUES (%s, %s, %s) ON DUPLICATE KEY UPDATE value = VALUES(value)"
+	}
+	_, err := s.db.Exec(s.query(upsert), s.store, name, value)
+	return err
+}
+
+// query fills the table name and the bind vars of format.
+func (s *SQLBlockstore) query(format string) string {
+	args := []interface{}{s.table}
+	for i := 1; i < countVerbs(format); i++ {
+		if s.dialect == DialectPostgres {
+			args = append(args, fmt.Sprintf("$%d", i))
+		} else {
+			args = append(args, "?")
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func countVerbs(format string) int {
+	n := 0
+	for i := 0; i+1 < len(format); i++ {
+		if format[i] == '%' && format[i+1] == 's' {
+			n++
+		}
+	}
+	return n
+}
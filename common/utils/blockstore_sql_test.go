@@ -0,0 +1,37 @@
+//go:build cgo
+
+package utils
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLBlockstore(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "blockstore.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	bs, err := NewSQLBlockstore(db, "cursors", DialectSQLite, 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCursorStorer(t, bs)
+
+	// another relayer sharing the table has its own rows
+	other, err := NewSQLBlockstore(db, "cursors", DialectSQLite, 1, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := other.GetCursor("pool/era"); err != nil || found {
+		t.Fatalf("cursor of another relayer found: %v %v", found, err)
+	}
+
+	if _, err := NewSQLBlockstore(db, "cursors; DROP TABLE cursors", DialectSQLite, 1, "relayer"); err == nil {
+		t.Fatal("expected invalid table name error")
+	}
+}
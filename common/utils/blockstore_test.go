@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testCursorStorer(t *testing.T, s CursorStorer) {
+	block, err := s.TryLoadLatestBlock()
+	if err != nil || block.Int64() != 0 {
+		t.Fatalf("empty store block: %v %v", block, err)
+	}
+	if err := s.StoreBlock(big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreSignature("sig"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutCursor("pool/era", "6"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutCursor("pool/era", "7"); err != nil {
+		t.Fatal(err)
+	}
+
+	block, err = s.TryLoadLatestBlock()
+	if err != nil || block.Int64() != 42 {
+		t.Fatalf("block overwritten: %v %v", block, err)
+	}
+	sig, err := s.TryLoadLatestSignature()
+	if err != nil || sig != "sig" {
+		t.Fatalf("unexpected signature: %q %v", sig, err)
+	}
+	value, found, err := s.GetCursor("pool/era")
+	if err != nil || !found || value != "7" {
+		t.Fatalf("unexpected cursor: %q %v %v", value, found, err)
+	}
+	_, found, err = s.GetCursor("missing")
+	if err != nil || found {
+		t.Fatalf("missing cursor found: %v %v", found, err)
+	}
+}
+
+func TestBlockstore(t *testing.T) {
+	dir := t.TempDir()
+	bs, err := NewBlockstore(dir, 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCursorStorer(t, bs)
+
+	if err := os.WriteFile(bs.fullPath, []byte("4x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bs.TryLoadLatestBlock(); err == nil {
+		t.Fatal("expected error on corrupted block")
+	}
+
+	// escaped names don't collide
+	if err := bs.PutCursor("pool_era", "8"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _, err := bs.GetCursor("pool/era"); err != nil || value != "7" {
+		t.Fatalf("pool/era cursor overwritten: %q %v", value, err)
+	}
+}
+
+func TestBlockstoreLegacySignature(t *testing.T) {
+	dir := t.TempDir()
+	bs, err := NewBlockstore(dir, 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the signature used to share the block file
+	if err := os.WriteFile(bs.fullPath, []byte("legacy-sig"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if sig, err := bs.TryLoadLatestSignature(); err != nil || sig != "legacy-sig" {
+		t.Fatalf("unexpected legacy signature: %q %v", sig, err)
+	}
+	// the migrated signature outlives the block file
+	if err := bs.StoreBlock(big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if sig, err := bs.TryLoadLatestSignature(); err != nil || sig != "legacy-sig" {
+		t.Fatalf("unexpected migrated signature: %q %v", sig, err)
+	}
+	if err := bs.StoreSignature("sig"); err != nil {
+		t.Fatal(err)
+	}
+	if sig, err := bs.TryLoadLatestSignature(); err != nil || sig != "sig" {
+		t.Fatalf("unexpected signature: %q %v", sig, err)
+	}
+}
+
+func TestBlockstoreBlockOnly(t *testing.T) {
+	bs, err := NewBlockstore(t.TempDir(), 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bs.StoreBlock(big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	if sig, err := bs.TryLoadLatestSignature(); err != nil || sig != "" {
+		t.Fatalf("block taken for a signature: %q %v", sig, err)
+	}
+	// reopening doesn't bring the legacy fallback back
+	bs, err = NewBlockstore(bs.path, 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig, err := bs.TryLoadLatestSignature(); err != nil || sig != "" {
+		t.Fatalf("block taken for a signature after reopen: %q %v", sig, err)
+	}
+}
+
+func TestBoltBlockstore(t *testing.T) {
+	bs, err := NewBoltBlockstore(filepath.Join(t.TempDir(), "blockstore.db"), 1, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bs.Close()
+	testCursorStorer(t, bs)
+}
@@ -0,0 +1,344 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxProcessed is the default bound of the unexpired hashes of a dedup set.
+	DefaultMaxProcessed = 10000
+	// processedChunkSize bounds the hashes of a chunk of a dedup set, a mark rewrites only
+	// the chunk it goes in
+	processedChunkSize = 256
+)
+
+// ErrProcessedSetFull is returned by MarkProcessed when the dedup set of a stream holds
+// the max number of unexpired hashes.
+var ErrProcessedSetFull = errors.New("processed set full")
+
+// Checkpoint is the last processed block of a stream. BlockHash lets a resuming scanner
+// detect that the block at Height was replaced by a reorg or a wrongly configured chain.
+type Checkpoint struct {
+	Height    int64     `json:"height"`
+	BlockHash string    `json:"block_hash,omitempty"`
+	BlockTime time.Time `json:"block_time,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StateStore keeps checkpoints and processed-tx dedup sets of any number of
+// (chain-id, stream) pairs in one CursorStorer backend.
+type StateStore struct {
+	backend CursorStorer
+
+	mutex        sync.Mutex
+	maxProcessed int
+	// dedup sets loaded from the backend, keyed by backend key
+	processed map[string]*processedSet
+	now       func() time.Time
+}
+
+// processedSet is a dedup set stored as chunks of at most processedChunkSize hashes under
+// <key>/<id> and the list of chunk ids under <key>. Chunks whose hashes all expired are
+// dropped from the list and their ids reused, so the number of backend keys stays bounded.
+type processedSet struct {
+	ids []int
+	// values are unix expiry seconds
+	chunks map[int]map[string]int64
+}
+
+func NewStateStore(backend CursorStorer) (*StateStore, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("backend is nil")
+	}
+	return &StateStore{
+		backend:      backend,
+		maxProcessed: DefaultMaxProcessed,
+		processed:    make(map[string]*processedSet),
+		now:          time.Now,
+	}, nil
+}
+
+// SetMaxProcessed bounds the unexpired hashes of every dedup set, 0 removes the bound.
+func (s *StateStore) SetMaxProcessed(max int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maxProcessed = max
+}
+
+// LoadCheckpoint returns found false if the stream has no checkpoint yet.
+func (s *StateStore) LoadCheckpoint(chainId, stream string) (Checkpoint, bool, error) {
+	cp := Checkpoint{}
+	value, found, err := s.backend.GetCursor(stateKey(chainId, stream, "checkpoint"))
+	if err != nil || !found {
+		return cp, false, err
+	}
+	if err := json.Unmarshal([]byte(value), &cp); err != nil {
+		return cp, false, fmt.Errorf("unmarshal checkpoint of %s/%s err: %w", chainId, stream, err)
+	}
+	return cp, true, nil
+}
+
+// SaveCheckpoint stores cp, UpdatedAt is set to now.
+func (s *StateStore) SaveCheckpoint(chainId, stream string, cp Checkpoint) error {
+	cp.UpdatedAt = s.now().UTC()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return s.backend.PutCursor(stateKey(chainId, stream, "checkpoint"), string(data))
+}
+
+// Cursor returns the checkpoint height of the stream, 0 if not found.
+func (s *StateStore) Cursor(chainId, stream string) (int64, error) {
+	cp, _, err := s.LoadCheckpoint(chainId, stream)
+	return cp.Height, err
+}
+
+// SetCursor stores a checkpoint with only the height.
+func (s *StateStore) SetCursor(chainId, stream string, height int64) error {
+	return s.SaveCheckpoint(chainId, stream, Checkpoint{Height: height})
+}
+
+// MarkProcessed records txHash in the dedup set of the stream for ttl. A set holds at most
+// DefaultMaxProcessed unexpired hashes unless changed by SetMaxProcessed, beyond it
+// ErrProcessedSetFull is returned instead of forgetting a hash that has not expired, so a
+// higher rate of marks needs a shorter ttl or a higher bound.
+func (s *StateStore) MarkProcessed(chainId, stream, txHash string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("ttl must be positive")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := stateKey(chainId, stream, "processed")
+	set, err := s.loadProcessed(key)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if err := s.dropExpiredChunks(key, set, now.Unix()); err != nil {
+		return err
+	}
+
+	// a hash marked again stays in its chunk
+	id, found := set.find(txHash)
+	if !found || set.chunks[id][txHash] <= now.Unix() {
+		if s.maxProcessed > 0 && set.live(now.Unix()) >= s.maxProcessed {
+			return fmt.Errorf("%w: %d unexpired hashes in %s/%s", ErrProcessedSetFull, s.maxProcessed, chainId, stream)
+		}
+	}
+	newChunk := false
+	if !found {
+		id, newChunk = set.openChunk(now.Unix())
+	}
+	chunk := make(map[string]int64, len(set.chunks[id])+1)
+	for hash, expiry := range set.chunks[id] {
+		if expiry > now.Unix() {
+			chunk[hash] = expiry
+		}
+	}
+	chunk[txHash] = now.Add(ttl).Unix()
+
+	// the chunk is written before it is listed, an unlisted chunk is overwritten on reuse
+	if err := s.putJSON(processedChunkKey(key, id), chunk); err != nil {
+		return err
+	}
+	if newChunk {
+		ids := append(append([]int{}, set.ids...), id)
+		sort.Ints(ids)
+		if err := s.putJSON(key, ids); err != nil {
+			return err
+		}
+		set.ids = ids
+	}
+	set.chunks[id] = chunk
+	return nil
+}
+
+// IsProcessed reports whether txHash was marked and has not expired.
+func (s *StateStore) IsProcessed(chainId, stream, txHash string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	set, err := s.loadProcessed(stateKey(chainId, stream, "processed"))
+	if err != nil {
+		return false, err
+	}
+	id, ok := set.find(txHash)
+	return ok && set.chunks[id][txHash] > s.now().Unix(), nil
+}
+
+// dropExpiredChunks unlists the chunks of set whose hashes all expired.
+func (s *StateStore) dropExpiredChunks(key string, set *processedSet, now int64) error {
+	ids := make([]int, 0, len(set.ids))
+	for _, id := range set.ids {
+		if liveHashes(set.chunks[id], now) != 0 {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == len(set.ids) {
+		return nil
+	}
+	if err := s.putJSON(key, ids); err != nil {
+		return err
+	}
+	for _, id := range set.ids {
+		if !containsInt(ids, id) {
+			delete(set.chunks, id)
+		}
+	}
+	set.ids = ids
+	return nil
+}
+
+func (s *StateStore) loadProcessed(key string) (*processedSet, error) {
+	if set, ok := s.processed[key]; ok {
+		return set, nil
+	}
+	set := &processedSet{ids: make([]int, 0), chunks: make(map[int]map[string]int64)}
+	if _, err := s.getJSON(key, &set.ids); err != nil {
+		return nil, err
+	}
+	for _, id := range set.ids {
+		chunk := make(map[string]int64)
+		if _, err := s.getJSON(processedChunkKey(key, id), &chunk); err != nil {
+			return nil, err
+		}
+		set.chunks[id] = chunk
+	}
+	s.processed[key] = set
+	return set, nil
+}
+
+func (s *StateStore) getJSON(key string, v interface{}) (bool, error) {
+	value, found, err := s.backend.GetCursor(key)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("unmarshal %s err: %w", key, err)
+	}
+	return true, nil
+}
+
+func (s *StateStore) putJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.backend.PutCursor(key, string(data))
+}
+
+// find returns the chunk holding txHash.
+func (p *processedSet) find(txHash string) (int, bool) {
+	for _, id := range p.ids {
+		if _, ok := p.chunks[id][txHash]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// live counts the hashes of all chunks that have not expired.
+func (p *processedSet) live(now int64) int {
+	count := 0
+	for _, chunk := range p.chunks {
+		count += liveHashes(chunk, now)
+	}
+	return count
+}
+
+// openChunk returns a chunk with room for an unexpired hash, otherwise the lowest unused
+// id, which must be listed once its chunk is written.
+func (p *processedSet) openChunk(now int64) (int, bool) {
+	for _, id := range p.ids {
+		if liveHashes(p.chunks[id], now) < processedChunkSize {
+			return id, false
+		}
+	}
+	id := 0
+	for containsInt(p.ids, id) {
+		id++
+	}
+	return id, true
+}
+
+func liveHashes(chunk map[string]int64, now int64) int {
+	count := 0
+	for _, expiry := range chunk {
+		if expiry > now {
+			count++
+		}
+	}
+	return count
+}
+
+func containsInt(list []int, v int) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func processedChunkKey(key string, id int) string {
+	return fmt.Sprintf("%s/%d", key, id)
+}
+
+// Stream returns a Blockstorer view of one stream, e.g. as the cursor store of a scanner.
+func (s *StateStore) Stream(chainId, stream string) *StreamStore {
+	return &StreamStore{state: s, chainId: chainId, stream: stream}
+}
+
+var _ Blockstorer = &StreamStore{}
+
+// StreamStore stores the block of one stream as its checkpoint height. StoreBlock stores
+// only the height, use SaveCheckpoint to keep the block hash and time along with it.
+type StreamStore struct {
+	state   *StateStore
+	chainId string
+	stream  string
+}
+
+func (s *StreamStore) StoreBlock(block *big.Int) error {
+	return s.state.SetCursor(s.chainId, s.stream, block.Int64())
+}
+
+func (s *StreamStore) StoreSignature(sig string) error {
+	return s.state.backend.PutCursor(stateKey(s.chainId, s.stream, "signature"), sig)
+}
+
+func (s *StreamStore) TryLoadLatestBlock() (*big.Int, error) {
+	height, err := s.state.Cursor(s.chainId, s.stream)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(height), nil
+}
+
+func (s *StreamStore) TryLoadLatestSignature() (string, error) {
+	sig, _, err := s.state.backend.GetCursor(stateKey(s.chainId, s.stream, "signature"))
+	return sig, err
+}
+
+func (s *StreamStore) LoadCheckpoint() (Checkpoint, bool, error) {
+	return s.state.LoadCheckpoint(s.chainId, s.stream)
+}
+
+func (s *StreamStore) SaveCheckpoint(cp Checkpoint) error {
+	return s.state.SaveCheckpoint(s.chainId, s.stream, cp)
+}
+
+// stateKey quotes chain id and stream so that separators inside them can't collide. This
+// relies on the backend keeping names apart, Blockstore escapes them into file names.
+func stateKey(chainId, stream, kind string) string {
+	return fmt.Sprintf("state/%s/%s/%s", strconv.Quote(chainId), strconv.Quote(stream), kind)
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStateStore(t *testing.T) {
+	bs, err := NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStateStore(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+
+	if err := s.SaveCheckpoint("neutron-1", "pool", Checkpoint{Height: 10, BlockHash: "AB"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetCursor("cosmoshub-4", "pool", 20); err != nil {
+		t.Fatal(err)
+	}
+	cp, found, err := s.LoadCheckpoint("neutron-1", "pool")
+	if err != nil || !found || cp.Height != 10 || cp.BlockHash != "AB" || !cp.UpdatedAt.Equal(now) {
+		t.Fatalf("unexpected checkpoint %+v %v %v", cp, found, err)
+	}
+	block, err := s.Stream("cosmoshub-4", "pool").TryLoadLatestBlock()
+	if err != nil || block.Int64() != 20 {
+		t.Fatalf("unexpected stream block %v %v", block, err)
+	}
+
+	if err := s.MarkProcessed("neutron-1", "pool", "TX1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if err := s.MarkProcessed("neutron-1", "pool", "TX2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(45 * time.Minute)
+
+	// reopen to read the persisted sets
+	s, _ = NewStateStore(bs)
+	s.now = func() time.Time { return now }
+	for hash, want := range map[string]bool{"TX1": false, "TX2": true, "TX3": false} {
+		got, err := s.IsProcessed("neutron-1", "pool", hash)
+		if err != nil || got != want {
+			t.Fatalf("IsProcessed(%s) = %v %v, want %v", hash, got, err, want)
+		}
+	}
+	if got, _ := s.IsProcessed("neutron-1", "other", "TX2"); got {
+		t.Fatal("dedup set leaked into another stream")
+	}
+}
+
+func TestMarkProcessedFull(t *testing.T) {
+	bs, err := NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStateStore(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	s.SetMaxProcessed(processedChunkSize + 1)
+
+	// fill a chunk and start a second one
+	for i := 0; i <= processedChunkSize; i++ {
+		if err := s.MarkProcessed("neutron-1", "pool", fmt.Sprintf("TX%d", i), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.MarkProcessed("neutron-1", "pool", "TXFULL", time.Hour); !errors.Is(err, ErrProcessedSetFull) {
+		t.Fatalf("expected full set err, got %v", err)
+	}
+	// marking a known hash again is not a new entry
+	if err := s.MarkProcessed("neutron-1", "pool", "TX0", 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.IsProcessed("neutron-1", "pool", "TX1"); !got {
+		t.Fatal("live hash dropped by a full set")
+	}
+
+	// the second chunk expires and is dropped, the first one kept by TX0 has room again
+	now = now.Add(90 * time.Minute)
+	if err := s.MarkProcessed("neutron-1", "pool", "TXFULL", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	if _, err := s.getJSON(stateKey("neutron-1", "pool", "processed"), &ids); err != nil || len(ids) != 1 || ids[0] != 0 {
+		t.Fatalf("unexpected chunk ids %v %v", ids, err)
+	}
+
+	s, _ = NewStateStore(bs)
+	s.now = func() time.Time { return now }
+	for hash, want := range map[string]bool{"TX0": true, "TX1": false, "TXFULL": true} {
+		if got, err := s.IsProcessed("neutron-1", "pool", hash); err != nil || got != want {
+			t.Fatalf("IsProcessed(%s) = %v %v, want %v", hash, got, err, want)
+		}
+	}
+}
+
+func TestStateKeyNoCollision(t *testing.T) {
+	bs, err := NewBlockstore(t.TempDir(), 0, "relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStateStore(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetCursor("neutron/1", "pool", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetCursor("neutron", "1/pool", 2); err != nil {
+		t.Fatal(err)
+	}
+	if height, err := s.Cursor("neutron/1", "pool"); err != nil || height != 1 {
+		t.Fatalf("cursor overwritten by another stream: %d %v", height, err)
+	}
+}